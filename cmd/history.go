@@ -0,0 +1,167 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/flashbots/node-healthchecker/config"
+	"github.com/flashbots/node-healthchecker/store"
+)
+
+const (
+	outputJSON = "json"
+	outputText = "text"
+)
+
+func CommandHistory(cfg *config.Config) *cli.Command {
+	var (
+		kinds    config.StringList
+		output   string
+		since    time.Duration
+		sources  config.StringList
+		statuses config.StringList
+		until    time.Duration
+	)
+
+	flags := []cli.Flag{
+		&cli.StringFlag{
+			Destination: &cfg.History.Store.File,
+			EnvVars:     []string{envPrefix + "HISTORY_STORE_FILE"},
+			Name:        "file",
+			Required:    true,
+			Usage:       "`path` to the history store (as written by the server with --history-store-file)",
+		},
+
+		&cli.GenericFlag{
+			Name:  "kind",
+			Usage: "print only the events of this `kind` (transition or sample, can be repeated)",
+			Value: &kinds,
+		},
+
+		&cli.StringFlag{
+			Destination: &output,
+			Name:        "output",
+			Usage:       "output `format` (text or json)",
+			Value:       outputText,
+		},
+
+		&cli.DurationFlag{
+			Destination: &since,
+			DefaultText: "all",
+			Name:        "since",
+			Usage:       "print only the events from the last `duration`",
+		},
+
+		&cli.GenericFlag{
+			Name:  "source",
+			Usage: "print only the events of this `source` (can be repeated)",
+			Value: &sources,
+		},
+
+		&cli.GenericFlag{
+			Name:  "status",
+			Usage: "print only the events with this `status` (ok, warning, error or unknown, can be repeated)",
+			Value: &statuses,
+		},
+
+		&cli.DurationFlag{
+			Destination: &until,
+			DefaultText: "now",
+			Name:        "until",
+			Usage:       "print only the events older than `duration`",
+		},
+	}
+
+	return &cli.Command{
+		Name:  "history",
+		Usage: "print the events from the history store",
+
+		Flags: flags,
+
+		Before: func(_ *cli.Context) error {
+			for _, kind := range kinds {
+				if kind != store.KindTransition && kind != store.KindSample {
+					return fmt.Errorf("invalid kind (expected '%s' or '%s'): %s",
+						store.KindTransition, store.KindSample, kind,
+					)
+				}
+			}
+			if output != outputText && output != outputJSON {
+				return fmt.Errorf("invalid output format (expected '%s' or '%s'): %s",
+					outputText, outputJSON, output,
+				)
+			}
+			return nil
+		},
+
+		Action: func(_ *cli.Context) error {
+			events, err := store.Load(cfg.History.Store.File)
+			if err != nil {
+				return err
+			}
+
+			now := time.Now()
+			events = slices.DeleteFunc(events, func(e *store.Event) bool {
+				switch {
+				case len(kinds) > 0 && !slices.Contains(kinds, e.Kind):
+					return true
+				case len(sources) > 0 && !slices.Contains(sources, e.Source):
+					return true
+				case len(statuses) > 0 && !slices.Contains(statuses, string(e.Status)):
+					return true
+				case since > 0 && e.Timestamp.Before(now.Add(-since)):
+					return true
+				case until > 0 && !e.Timestamp.Before(now.Add(-until)):
+					return true
+				}
+				return false
+			})
+
+			if output == outputJSON {
+				enc := json.NewEncoder(os.Stdout)
+				for _, event := range events {
+					if err := enc.Encode(event); err != nil {
+						return err
+					}
+				}
+				return nil
+			}
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "TIMESTAMP\tSOURCE\tKIND\tSTATUS\tDURATION\tMESSAGE")
+			for _, event := range events {
+				status := string(event.Status)
+				if event.Kind == store.KindTransition {
+					status = string(event.From) + " -> " + status
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+					event.Timestamp.UTC().Format(time.RFC3339),
+					event.Source,
+					event.Kind,
+					status,
+					formatEventDuration(event.Kind, event.Duration),
+					strings.ReplaceAll(event.Message, "\n", " "),
+				)
+			}
+			return w.Flush()
+		},
+	}
+}
+
+func formatEventDuration(kind string, d time.Duration) string {
+	switch {
+	case d == 0:
+		return "-"
+	case kind == store.KindTransition:
+		return d.Round(time.Second).String()
+	default:
+		return d.Round(time.Microsecond).String()
+	}
+}
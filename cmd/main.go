@@ -42,6 +42,7 @@ func main() {
 
 	commands := []*cli.Command{
 		CommandServe(cfg),
+		CommandHistory(cfg),
 		CommandHelp(cfg),
 	}
 
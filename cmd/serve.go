@@ -14,13 +14,24 @@ import (
 )
 
 const (
+	categoryConsul                = "consul"
+	categoryDrain                 = "drain"
+	categoryGRPC                  = "grpc"
+	categoryHAProxyAgent          = "haproxy agent"
 	categoryHealthcheck           = "healthcheck"
 	categoryHealthcheckGeth       = "healthcheck geth"
 	categoryHealthcheckLighthouse = "healthcheck lighthouse"
 	categoryHealthcheckOpNode     = "healthcheck op-node"
 	categoryHealthcheckReth       = "healthcheck reth"
+	categoryHistory               = "history"
 	categoryHttpStatus            = "http status"
+	categoryKubernetes            = "kubernetes"
+	categoryMetrics               = "metrics"
+	categoryPolicy                = "policy"
 	categoryServer                = "server"
+	categorySLO                   = "slo"
+	categoryTracing               = "tracing"
+	categoryWebhook               = "webhook"
 )
 
 func CommandServe(cfg *config.Config) *cli.Command {
@@ -28,6 +39,171 @@ func CommandServe(cfg *config.Config) *cli.Command {
 	if ipv4, err := utils.PrivateIPv4(); err == nil {
 		ip = ipv4.String()
 	}
+	// consul
+
+	consulFlags := []cli.Flag{
+		&cli.StringFlag{
+			Category:    strings.ToUpper(categoryConsul),
+			Destination: &cfg.Consul.Address,
+			DefaultText: "disabled",
+			EnvVars:     []string{envPrefix + strings.ToUpper(categoryConsul) + "_ADDRESS"},
+			Name:        categoryConsul + "-address",
+			Usage:       "`url` of the local consul agent to register the node's service with",
+		},
+
+		&cli.DurationFlag{
+			Category:    strings.ToUpper(categoryConsul),
+			Destination: &cfg.Consul.CheckTTL,
+			EnvVars:     []string{envPrefix + strings.ToUpper(categoryConsul) + "_CHECK_TTL"},
+			Name:        categoryConsul + "-check-ttl",
+			Usage:       "`ttl` of the consul check (it is refreshed 3 times as often)",
+			Value:       30 * time.Second,
+		},
+
+		&cli.DurationFlag{
+			Category:    strings.ToUpper(categoryConsul),
+			Destination: &cfg.Consul.DeregisterCriticalServiceAfter,
+			DefaultText: "never",
+			EnvVars:     []string{envPrefix + strings.ToUpper(categoryConsul) + "_DEREGISTER_CRITICAL_SERVICE_AFTER"},
+			Name:        categoryConsul + "-deregister-critical-service-after",
+			Usage:       "`duration` after which consul removes the service with failing check",
+		},
+
+		&cli.StringFlag{
+			Category:    strings.ToUpper(categoryConsul),
+			Destination: &cfg.Consul.ServiceAddress,
+			EnvVars:     []string{envPrefix + strings.ToUpper(categoryConsul) + "_SERVICE_ADDRESS"},
+			Name:        categoryConsul + "-service-address",
+			Usage:       "`address` of the service to register (defaults to the address of consul agent)",
+		},
+
+		&cli.StringFlag{
+			Category:    strings.ToUpper(categoryConsul),
+			Destination: &cfg.Consul.ServiceID,
+			DefaultText: "<service-name>-<hostname>",
+			EnvVars:     []string{envPrefix + strings.ToUpper(categoryConsul) + "_SERVICE_ID"},
+			Name:        categoryConsul + "-service-id",
+			Usage:       "`id` of the service to register",
+		},
+
+		&cli.StringFlag{
+			Category:    strings.ToUpper(categoryConsul),
+			Destination: &cfg.Consul.ServiceName,
+			EnvVars:     []string{envPrefix + strings.ToUpper(categoryConsul) + "_SERVICE_NAME"},
+			Name:        categoryConsul + "-service-name",
+			Usage:       "`name` of the service to register",
+		},
+
+		&cli.IntFlag{
+			Category:    strings.ToUpper(categoryConsul),
+			Destination: &cfg.Consul.ServicePort,
+			EnvVars:     []string{envPrefix + strings.ToUpper(categoryConsul) + "_SERVICE_PORT"},
+			Name:        categoryConsul + "-service-port",
+			Usage:       "`port` of the service to register",
+		},
+
+		&cli.GenericFlag{
+			Category: strings.ToUpper(categoryConsul),
+			EnvVars:  []string{envPrefix + strings.ToUpper(categoryConsul) + "_SERVICE_TAGS"},
+			Name:     categoryConsul + "-service-tags",
+			Usage:    "comma-separated `list` of the tags of the service to register",
+			Value:    &cfg.Consul.ServiceTags,
+		},
+
+		&cli.DurationFlag{
+			Category:    strings.ToUpper(categoryConsul),
+			Destination: &cfg.Consul.Timeout,
+			EnvVars:     []string{envPrefix + strings.ToUpper(categoryConsul) + "_TIMEOUT"},
+			Name:        categoryConsul + "-timeout",
+			Usage:       "`timeout` of the requests to consul agent",
+			Value:       5 * time.Second,
+		},
+
+		&cli.StringFlag{
+			Category:    strings.ToUpper(categoryConsul),
+			Destination: &cfg.Consul.Token,
+			DefaultText: "none",
+			EnvVars:     []string{envPrefix + strings.ToUpper(categoryConsul) + "_TOKEN"},
+			Name:        categoryConsul + "-token",
+			Usage:       "acl `token` to authenticate with consul agent",
+		},
+	}
+
+	// drain
+
+	drainFlags := []cli.Flag{
+		&cli.StringFlag{
+			Category:    strings.ToUpper(categoryDrain),
+			Destination: &cfg.Drain.AdminToken,
+			DefaultText: "disabled",
+			EnvVars:     []string{envPrefix + strings.ToUpper(categoryDrain) + "_ADMIN_TOKEN"},
+			Name:        categoryDrain + "-admin-token",
+			Usage:       "enable the /admin/drain endpoint with the bearer `token` required by it",
+		},
+
+		&cli.IntFlag{
+			Category:    strings.ToUpper(categoryDrain),
+			Destination: &cfg.Drain.HttpStatus,
+			EnvVars:     []string{envPrefix + strings.ToUpper(categoryDrain) + "_HTTP_STATUS"},
+			Name:        categoryDrain + "-http-status",
+			Usage:       "http `status` to report while in drain mode",
+			Value:       http.StatusServiceUnavailable,
+		},
+
+		&cli.StringFlag{
+			Category:    strings.ToUpper(categoryDrain),
+			Destination: &cfg.Drain.StateFile,
+			DefaultText: "none",
+			EnvVars:     []string{envPrefix + strings.ToUpper(categoryDrain) + "_STATE_FILE"},
+			Name:        categoryDrain + "-state-file",
+			Usage:       "`path` to the file to persist the drain mode in (so that it survives restarts)",
+		},
+	}
+
+	// grpc
+
+	grpcFlags := []cli.Flag{
+		&cli.StringFlag{
+			Category:    strings.ToUpper(categoryGRPC),
+			Destination: &cfg.GRPC.ListenAddress,
+			DefaultText: "disabled",
+			EnvVars:     []string{envPrefix + strings.ToUpper(categoryGRPC) + "_LISTEN_ADDRESS"},
+			Name:        categoryGRPC + "-listen-address",
+			Usage:       "`host:port` for grpc health checking protocol listener (grpc.health.v1.Health)",
+		},
+	}
+
+	// haproxy agent
+
+	haproxyAgentFlags := []cli.Flag{
+		&cli.StringFlag{
+			Category:    strings.ToUpper(categoryHAProxyAgent),
+			Destination: &cfg.HAProxyAgent.ListenAddress,
+			DefaultText: "disabled",
+			EnvVars:     []string{envPrefix + strings.ReplaceAll(strings.ToUpper(categoryHAProxyAgent), " ", "_") + "_LISTEN_ADDRESS"},
+			Name:        strings.ReplaceAll(categoryHAProxyAgent, " ", "-") + "-listen-address",
+			Usage:       "`host:port` for haproxy agent-check tcp listener (responds with 'up <weight>', 'down' or 'drain')",
+		},
+
+		&cli.StringFlag{
+			Category:    strings.ToUpper(categoryHAProxyAgent),
+			Destination: &cfg.HAProxyAgent.WeightOk,
+			EnvVars:     []string{envPrefix + strings.ReplaceAll(strings.ToUpper(categoryHAProxyAgent), " ", "_") + "_WEIGHT_OK"},
+			Name:        strings.ReplaceAll(categoryHAProxyAgent, " ", "-") + "-weight-ok",
+			Usage:       "`weight` to report to haproxy on good healthchecks",
+			Value:       "100%",
+		},
+
+		&cli.StringFlag{
+			Category:    strings.ToUpper(categoryHAProxyAgent),
+			Destination: &cfg.HAProxyAgent.WeightWarning,
+			EnvVars:     []string{envPrefix + strings.ReplaceAll(strings.ToUpper(categoryHAProxyAgent), " ", "_") + "_WEIGHT_WARNING"},
+			Name:        strings.ReplaceAll(categoryHAProxyAgent, " ", "-") + "-weight-warning",
+			Usage:       "`weight` to report to haproxy on warnings in the healthchecks",
+			Value:       "50%",
+		},
+	}
+
 	// healthcheck
 
 	healthcheckFlags := []cli.Flag{
@@ -50,6 +226,26 @@ func CommandServe(cfg *config.Config) *cli.Command {
 			Value:       750 * time.Millisecond,
 		},
 
+		&cli.DurationFlag{
+			Category:    strings.ToUpper(categoryHealthcheck),
+			Destination: &cfg.Healthcheck.Interval,
+			DefaultText: "disabled",
+			EnvVars:     []string{envPrefix + strings.ToUpper(categoryHealthcheck) + "_INTERVAL"},
+			Name:        categoryHealthcheck + "-interval",
+			Usage:       "run healthchecks in background every `duration` (so that state changes are detected without incoming requests)",
+			Value:       0,
+		},
+
+		&cli.DurationFlag{
+			Category:    strings.ToUpper(categoryHealthcheck),
+			Destination: &cfg.Healthcheck.StaleWhileRevalidate,
+			DefaultText: "disabled",
+			EnvVars:     []string{envPrefix + strings.ToUpper(categoryHealthcheck) + "_STALE_WHILE_REVALIDATE"},
+			Name:        categoryHealthcheck + "-stale-while-revalidate",
+			Usage:       "keep serving the expired cached results for up to the specified `duration` while they are being refreshed in background",
+			Value:       0,
+		},
+
 		&cli.DurationFlag{
 			Category:    strings.ToUpper(categoryHealthcheck),
 			Destination: &cfg.Healthcheck.Timeout,
@@ -63,6 +259,50 @@ func CommandServe(cfg *config.Config) *cli.Command {
 	// healthcheck geth
 
 	healthcheckGethFlags := []cli.Flag{
+		&cli.BoolFlag{
+			Category:    strings.ToUpper(categoryHealthcheckGeth),
+			Destination: &cfg.HealthcheckGeth.Archive.Enabled,
+			EnvVars:     []string{envPrefix + strings.ReplaceAll(strings.ToUpper(categoryHealthcheckGeth), " ", "_") + "_ARCHIVE"},
+			Name:        strings.ReplaceAll(categoryHealthcheckGeth, " ", "-") + "-archive",
+			Usage:       "verify that geth still serves historical state (archive node)",
+		},
+
+		&cli.StringFlag{
+			Category:    strings.ToUpper(categoryHealthcheckGeth),
+			Destination: &cfg.HealthcheckGeth.Archive.Address,
+			DefaultText: "0x0000000000000000000000000000000000000000",
+			EnvVars:     []string{envPrefix + strings.ReplaceAll(strings.ToUpper(categoryHealthcheckGeth), " ", "_") + "_ARCHIVE_ADDRESS"},
+			Name:        strings.ReplaceAll(categoryHealthcheckGeth, " ", "-") + "-archive-address",
+			Usage:       "`address` to query the historical balance (or storage) of",
+		},
+
+		&cli.Uint64Flag{
+			Category:    strings.ToUpper(categoryHealthcheckGeth),
+			Destination: &cfg.HealthcheckGeth.Archive.BlockFrom,
+			DefaultText: "none",
+			EnvVars:     []string{envPrefix + strings.ReplaceAll(strings.ToUpper(categoryHealthcheckGeth), " ", "_") + "_ARCHIVE_BLOCK_FROM"},
+			Name:        strings.ReplaceAll(categoryHealthcheckGeth, " ", "-") + "-archive-block-from",
+			Usage:       "historical block `number` (well beyond the pruning horizon) to query the state at, or the start of the range to pick it randomly from (required with archive)",
+		},
+
+		&cli.Uint64Flag{
+			Category:    strings.ToUpper(categoryHealthcheckGeth),
+			Destination: &cfg.HealthcheckGeth.Archive.BlockTo,
+			DefaultText: "same as block-from",
+			EnvVars:     []string{envPrefix + strings.ReplaceAll(strings.ToUpper(categoryHealthcheckGeth), " ", "_") + "_ARCHIVE_BLOCK_TO"},
+			Name:        strings.ReplaceAll(categoryHealthcheckGeth, " ", "-") + "-archive-block-to",
+			Usage:       "end `number` (inclusive) of the range to randomly pick the historical block from",
+		},
+
+		&cli.StringFlag{
+			Category:    strings.ToUpper(categoryHealthcheckGeth),
+			Destination: &cfg.HealthcheckGeth.Archive.StorageSlot,
+			DefaultText: "query the balance",
+			EnvVars:     []string{envPrefix + strings.ReplaceAll(strings.ToUpper(categoryHealthcheckGeth), " ", "_") + "_ARCHIVE_STORAGE_SLOT"},
+			Name:        strings.ReplaceAll(categoryHealthcheckGeth, " ", "-") + "-archive-storage-slot",
+			Usage:       "storage `slot` to query with eth_getStorageAt instead of eth_getBalance",
+		},
+
 		&cli.StringFlag{
 			Category:    strings.ToUpper(categoryHealthcheckGeth),
 			Destination: &cfg.HealthcheckGeth.BaseURL,
@@ -70,6 +310,110 @@ func CommandServe(cfg *config.Config) *cli.Command {
 			Name:        strings.ReplaceAll(categoryHealthcheckGeth, " ", "-") + "-base-url",
 			Usage:       "base `url` of geth's HTTP-RPC endpoint",
 		},
+
+		&cli.DurationFlag{
+			Category:    strings.ToUpper(categoryHealthcheckGeth),
+			Destination: &cfg.HealthcheckGeth.CacheCoolOff,
+			DefaultText: "--" + categoryHealthcheck + "-cache-cool-off",
+			EnvVars:     []string{envPrefix + strings.ReplaceAll(strings.ToUpper(categoryHealthcheckGeth), " ", "_") + "_CACHE_COOL_OFF"},
+			Name:        strings.ReplaceAll(categoryHealthcheckGeth, " ", "-") + "-cache-cool-off",
+			Usage:       "re-use geth's healthcheck results for the specified `duration`",
+		},
+
+		&cli.Uint64Flag{
+			Category:    strings.ToUpper(categoryHealthcheckGeth),
+			Destination: &cfg.HealthcheckGeth.ChainID,
+			DefaultText: "disabled",
+			EnvVars:     []string{envPrefix + strings.ReplaceAll(strings.ToUpper(categoryHealthcheckGeth), " ", "_") + "_CHAIN_ID"},
+			Name:        strings.ReplaceAll(categoryHealthcheckGeth, " ", "-") + "-chain-id",
+			Usage:       "report unhealthy if geth's chain id (as per eth_chainId) is different from the expected `id`",
+		},
+
+		&cli.GenericFlag{
+			Category: strings.ToUpper(categoryHealthcheckGeth),
+			EnvVars:  []string{envPrefix + strings.ReplaceAll(strings.ToUpper(categoryHealthcheckGeth), " ", "_") + "_DEPENDS_ON"},
+			Name:     strings.ReplaceAll(categoryHealthcheckGeth, " ", "-") + "-depends-on",
+			Usage:    "skip geth's healthcheck (and report it as such) when the healthcheck of the `source` it depends on fails (can be repeated)",
+			Value:    &cfg.HealthcheckGeth.DependsOn,
+		},
+
+		&cli.StringFlag{
+			Category:    strings.ToUpper(categoryHealthcheckGeth),
+			Destination: &cfg.HealthcheckGeth.Engine.BaseURL,
+			DefaultText: "disabled",
+			EnvVars:     []string{envPrefix + strings.ReplaceAll(strings.ToUpper(categoryHealthcheckGeth), " ", "_") + "_ENGINE_BASE_URL"},
+			Name:        strings.ReplaceAll(categoryHealthcheckGeth, " ", "-") + "-engine-base-url",
+			Usage:       "base `url` of geth's engine api (authrpc) endpoint",
+		},
+
+		&cli.BoolFlag{
+			Category:    strings.ToUpper(categoryHealthcheckGeth),
+			Destination: &cfg.HealthcheckGeth.Engine.ClientVersion,
+			EnvVars:     []string{envPrefix + strings.ReplaceAll(strings.ToUpper(categoryHealthcheckGeth), " ", "_") + "_ENGINE_CLIENT_VERSION"},
+			Name:        strings.ReplaceAll(categoryHealthcheckGeth, " ", "-") + "-engine-client-version",
+			Usage:       "additionally call engine_getClientVersionV1 on geth's engine api",
+		},
+
+		&cli.StringFlag{
+			Category:    strings.ToUpper(categoryHealthcheckGeth),
+			Destination: &cfg.HealthcheckGeth.Engine.JWTSecretPath,
+			EnvVars:     []string{envPrefix + strings.ReplaceAll(strings.ToUpper(categoryHealthcheckGeth), " ", "_") + "_ENGINE_JWT_SECRET"},
+			Name:        strings.ReplaceAll(categoryHealthcheckGeth, " ", "-") + "-engine-jwt-secret",
+			Usage:       "`path` to the jwt secret shared with geth's engine api",
+		},
+
+		&cli.StringFlag{
+			Category:    strings.ToUpper(categoryHealthcheckGeth),
+			Destination: &cfg.HealthcheckGeth.NetVersion,
+			DefaultText: "disabled",
+			EnvVars:     []string{envPrefix + strings.ReplaceAll(strings.ToUpper(categoryHealthcheckGeth), " ", "_") + "_NET_VERSION"},
+			Name:        strings.ReplaceAll(categoryHealthcheckGeth, " ", "-") + "-net-version",
+			Usage:       "report unhealthy if geth's network id (as per net_version) is different from the expected `id`",
+		},
+
+		&cli.UintFlag{
+			Category:    strings.ToUpper(categoryHealthcheckGeth),
+			Destination: &cfg.HealthcheckGeth.Retry.Attempts,
+			EnvVars:     []string{envPrefix + strings.ReplaceAll(strings.ToUpper(categoryHealthcheckGeth), " ", "_") + "_RETRY_ATTEMPTS"},
+			Name:        strings.ReplaceAll(categoryHealthcheckGeth, " ", "-") + "-retry-attempts",
+			Usage:       "maximum `count` of attempts of each request to geth (the requests that failed with connection error, timeout or HTTP 5xx are retried within the healthcheck timeout)",
+			Value:       1,
+		},
+
+		&cli.DurationFlag{
+			Category:    strings.ToUpper(categoryHealthcheckGeth),
+			Destination: &cfg.HealthcheckGeth.Retry.Backoff,
+			EnvVars:     []string{envPrefix + strings.ReplaceAll(strings.ToUpper(categoryHealthcheckGeth), " ", "_") + "_RETRY_BACKOFF"},
+			Name:        strings.ReplaceAll(categoryHealthcheckGeth, " ", "-") + "-retry-backoff",
+			Usage:       "initial `duration` to back off for before retrying a request to geth (doubled with each attempt, with jitter)",
+			Value:       100 * time.Millisecond,
+		},
+
+		&cli.GenericFlag{
+			Category: strings.ToUpper(categoryHealthcheckGeth),
+			EnvVars:  []string{envPrefix + strings.ReplaceAll(strings.ToUpper(categoryHealthcheckGeth), " ", "_") + "_SMOKE_TEST"},
+			Name:     strings.ReplaceAll(categoryHealthcheckGeth, " ", "-") + "-smoke-test",
+			Usage:    "JSON-RPC call (as `json` with method, params, path, equals, matches, max_latency and severity) to run against geth and assert upon (can be repeated)",
+			Value:    &cfg.HealthcheckGeth.SmokeTests,
+		},
+
+		&cli.DurationFlag{
+			Category:    strings.ToUpper(categoryHealthcheckGeth),
+			Destination: &cfg.HealthcheckGeth.Timeout,
+			DefaultText: "--" + categoryHealthcheck + "-timeout",
+			EnvVars:     []string{envPrefix + strings.ReplaceAll(strings.ToUpper(categoryHealthcheckGeth), " ", "_") + "_TIMEOUT"},
+			Name:        strings.ReplaceAll(categoryHealthcheckGeth, " ", "-") + "-timeout",
+			Usage:       "maximum `duration` of geth's healthcheck",
+		},
+
+		&cli.StringFlag{
+			Category:    strings.ToUpper(categoryHealthcheckGeth),
+			Destination: &cfg.HealthcheckGeth.WebsocketURL,
+			DefaultText: "disabled",
+			EnvVars:     []string{envPrefix + strings.ReplaceAll(strings.ToUpper(categoryHealthcheckGeth), " ", "_") + "_WEBSOCKET_URL"},
+			Name:        strings.ReplaceAll(categoryHealthcheckGeth, " ", "-") + "-websocket-url",
+			Usage:       "`url` of geth's WS-RPC endpoint to track the latest block via newHeads subscription instead of polling (requires the block age threshold)",
+		},
 	}
 
 	// healthcheck lighthouse
@@ -82,6 +426,68 @@ func CommandServe(cfg *config.Config) *cli.Command {
 			Name:        strings.ReplaceAll(categoryHealthcheckLighthouse, " ", "-") + "-base-url",
 			Usage:       "base `url` of lighthouse's HTTP-API endpoint",
 		},
+
+		&cli.DurationFlag{
+			Category:    strings.ToUpper(categoryHealthcheckLighthouse),
+			Destination: &cfg.HealthcheckLighthouse.CacheCoolOff,
+			DefaultText: "--" + categoryHealthcheck + "-cache-cool-off",
+			EnvVars:     []string{envPrefix + strings.ReplaceAll(strings.ToUpper(categoryHealthcheckLighthouse), " ", "_") + "_CACHE_COOL_OFF"},
+			Name:        strings.ReplaceAll(categoryHealthcheckLighthouse, " ", "-") + "-cache-cool-off",
+			Usage:       "re-use lighthouse's healthcheck results for the specified `duration`",
+		},
+
+		&cli.GenericFlag{
+			Category: strings.ToUpper(categoryHealthcheckLighthouse),
+			EnvVars:  []string{envPrefix + strings.ReplaceAll(strings.ToUpper(categoryHealthcheckLighthouse), " ", "_") + "_DEPENDS_ON"},
+			Name:     strings.ReplaceAll(categoryHealthcheckLighthouse, " ", "-") + "-depends-on",
+			Usage:    "skip lighthouse's healthcheck (and report it as such) when the healthcheck of the `source` it depends on fails (can be repeated)",
+			Value:    &cfg.HealthcheckLighthouse.DependsOn,
+		},
+
+		&cli.StringFlag{
+			Category:    strings.ToUpper(categoryHealthcheckLighthouse),
+			Destination: &cfg.HealthcheckLighthouse.GenesisForkVersion,
+			DefaultText: "disabled",
+			EnvVars:     []string{envPrefix + strings.ReplaceAll(strings.ToUpper(categoryHealthcheckLighthouse), " ", "_") + "_GENESIS_FORK_VERSION"},
+			Name:        strings.ReplaceAll(categoryHealthcheckLighthouse, " ", "-") + "-genesis-fork-version",
+			Usage:       "report unhealthy if lighthouse's genesis fork version is different from the expected `hex`",
+		},
+
+		&cli.StringFlag{
+			Category:    strings.ToUpper(categoryHealthcheckLighthouse),
+			Destination: &cfg.HealthcheckLighthouse.GenesisValidatorsRoot,
+			DefaultText: "disabled",
+			EnvVars:     []string{envPrefix + strings.ReplaceAll(strings.ToUpper(categoryHealthcheckLighthouse), " ", "_") + "_GENESIS_VALIDATORS_ROOT"},
+			Name:        strings.ReplaceAll(categoryHealthcheckLighthouse, " ", "-") + "-genesis-validators-root",
+			Usage:       "report unhealthy if lighthouse's genesis validators root is different from the expected `hex`",
+		},
+
+		&cli.UintFlag{
+			Category:    strings.ToUpper(categoryHealthcheckLighthouse),
+			Destination: &cfg.HealthcheckLighthouse.Retry.Attempts,
+			EnvVars:     []string{envPrefix + strings.ReplaceAll(strings.ToUpper(categoryHealthcheckLighthouse), " ", "_") + "_RETRY_ATTEMPTS"},
+			Name:        strings.ReplaceAll(categoryHealthcheckLighthouse, " ", "-") + "-retry-attempts",
+			Usage:       "maximum `count` of attempts of each request to lighthouse (the requests that failed with connection error, timeout or HTTP 5xx are retried within the healthcheck timeout)",
+			Value:       1,
+		},
+
+		&cli.DurationFlag{
+			Category:    strings.ToUpper(categoryHealthcheckLighthouse),
+			Destination: &cfg.HealthcheckLighthouse.Retry.Backoff,
+			EnvVars:     []string{envPrefix + strings.ReplaceAll(strings.ToUpper(categoryHealthcheckLighthouse), " ", "_") + "_RETRY_BACKOFF"},
+			Name:        strings.ReplaceAll(categoryHealthcheckLighthouse, " ", "-") + "-retry-backoff",
+			Usage:       "initial `duration` to back off for before retrying a request to lighthouse (doubled with each attempt, with jitter)",
+			Value:       100 * time.Millisecond,
+		},
+
+		&cli.DurationFlag{
+			Category:    strings.ToUpper(categoryHealthcheckLighthouse),
+			Destination: &cfg.HealthcheckLighthouse.Timeout,
+			DefaultText: "--" + categoryHealthcheck + "-timeout",
+			EnvVars:     []string{envPrefix + strings.ReplaceAll(strings.ToUpper(categoryHealthcheckLighthouse), " ", "_") + "_TIMEOUT"},
+			Name:        strings.ReplaceAll(categoryHealthcheckLighthouse, " ", "-") + "-timeout",
+			Usage:       "maximum `duration` of lighthouse's healthcheck",
+		},
 	}
 
 	// healthcheck op-node
@@ -95,6 +501,24 @@ func CommandServe(cfg *config.Config) *cli.Command {
 			Usage:       "base `url` of op-node's RPC endpoint",
 		},
 
+		&cli.DurationFlag{
+			Category:    strings.ToUpper(categoryHealthcheckOpNode),
+			Destination: &cfg.HealthcheckOpNode.CacheCoolOff,
+			DefaultText: "--" + categoryHealthcheck + "-cache-cool-off",
+			EnvVars:     []string{envPrefix + strings.ReplaceAll(strings.ReplaceAll(strings.ToUpper(categoryHealthcheckOpNode), " ", "_"), "-", "_") + "_CACHE_COOL_OFF"},
+			Name:        strings.ReplaceAll(categoryHealthcheckOpNode, " ", "-") + "-cache-cool-off",
+			Usage:       "re-use op-node's healthcheck results for the specified `duration`",
+		},
+
+		&cli.Uint64Flag{
+			Category:    strings.ToUpper(categoryHealthcheckOpNode),
+			Destination: &cfg.HealthcheckOpNode.ChainID,
+			DefaultText: "disabled",
+			EnvVars:     []string{envPrefix + strings.ReplaceAll(strings.ReplaceAll(strings.ToUpper(categoryHealthcheckOpNode), " ", "_"), "-", "_") + "_CHAIN_ID"},
+			Name:        strings.ReplaceAll(categoryHealthcheckOpNode, " ", "-") + "-chain-id",
+			Usage:       "report unhealthy if op-node's l2 chain id (as per rollup config) is different from the expected `id`",
+		},
+
 		&cli.Uint64Flag{
 			Category:    strings.ToUpper(categoryHealthcheckOpNode),
 			Destination: &cfg.HealthcheckOpNode.ConfirmationDistance,
@@ -103,11 +527,99 @@ func CommandServe(cfg *config.Config) *cli.Command {
 			Usage:       "number of l1 blocks that verifier keeps distance from the l1 head before deriving l2 data from",
 			Value:       0,
 		},
+
+		&cli.GenericFlag{
+			Category: strings.ToUpper(categoryHealthcheckOpNode),
+			EnvVars:  []string{envPrefix + strings.ReplaceAll(strings.ReplaceAll(strings.ToUpper(categoryHealthcheckOpNode), " ", "_"), "-", "_") + "_DEPENDS_ON"},
+			Name:     strings.ReplaceAll(categoryHealthcheckOpNode, " ", "-") + "-depends-on",
+			Usage:    "skip op-node's healthcheck (and report it as such) when the healthcheck of the `source` it depends on fails (can be repeated)",
+			Value:    &cfg.HealthcheckOpNode.DependsOn,
+		},
+
+		&cli.Uint64Flag{
+			Category:    strings.ToUpper(categoryHealthcheckOpNode),
+			Destination: &cfg.HealthcheckOpNode.L1ChainID,
+			DefaultText: "disabled",
+			EnvVars:     []string{envPrefix + strings.ReplaceAll(strings.ReplaceAll(strings.ToUpper(categoryHealthcheckOpNode), " ", "_"), "-", "_") + "_L1_CHAIN_ID"},
+			Name:        strings.ReplaceAll(categoryHealthcheckOpNode, " ", "-") + "-l1-chain-id",
+			Usage:       "report unhealthy if op-node's l1 chain id (as per rollup config) is different from the expected `id`",
+		},
+
+		&cli.UintFlag{
+			Category:    strings.ToUpper(categoryHealthcheckOpNode),
+			Destination: &cfg.HealthcheckOpNode.Retry.Attempts,
+			EnvVars:     []string{envPrefix + strings.ReplaceAll(strings.ReplaceAll(strings.ToUpper(categoryHealthcheckOpNode), " ", "_"), "-", "_") + "_RETRY_ATTEMPTS"},
+			Name:        strings.ReplaceAll(categoryHealthcheckOpNode, " ", "-") + "-retry-attempts",
+			Usage:       "maximum `count` of attempts of each request to op-node (the requests that failed with connection error, timeout or HTTP 5xx are retried within the healthcheck timeout)",
+			Value:       1,
+		},
+
+		&cli.DurationFlag{
+			Category:    strings.ToUpper(categoryHealthcheckOpNode),
+			Destination: &cfg.HealthcheckOpNode.Retry.Backoff,
+			EnvVars:     []string{envPrefix + strings.ReplaceAll(strings.ReplaceAll(strings.ToUpper(categoryHealthcheckOpNode), " ", "_"), "-", "_") + "_RETRY_BACKOFF"},
+			Name:        strings.ReplaceAll(categoryHealthcheckOpNode, " ", "-") + "-retry-backoff",
+			Usage:       "initial `duration` to back off for before retrying a request to op-node (doubled with each attempt, with jitter)",
+			Value:       100 * time.Millisecond,
+		},
+
+		&cli.DurationFlag{
+			Category:    strings.ToUpper(categoryHealthcheckOpNode),
+			Destination: &cfg.HealthcheckOpNode.Timeout,
+			DefaultText: "--" + categoryHealthcheck + "-timeout",
+			EnvVars:     []string{envPrefix + strings.ReplaceAll(strings.ReplaceAll(strings.ToUpper(categoryHealthcheckOpNode), " ", "_"), "-", "_") + "_TIMEOUT"},
+			Name:        strings.ReplaceAll(categoryHealthcheckOpNode, " ", "-") + "-timeout",
+			Usage:       "maximum `duration` of op-node's healthcheck",
+		},
 	}
 
 	// healthcheck reth
 
 	healthcheckRethFlags := []cli.Flag{
+		&cli.BoolFlag{
+			Category:    strings.ToUpper(categoryHealthcheckReth),
+			Destination: &cfg.HealthcheckReth.Archive.Enabled,
+			EnvVars:     []string{envPrefix + strings.ReplaceAll(strings.ToUpper(categoryHealthcheckReth), " ", "_") + "_ARCHIVE"},
+			Name:        strings.ReplaceAll(categoryHealthcheckReth, " ", "-") + "-archive",
+			Usage:       "verify that reth still serves historical state (archive node)",
+		},
+
+		&cli.StringFlag{
+			Category:    strings.ToUpper(categoryHealthcheckReth),
+			Destination: &cfg.HealthcheckReth.Archive.Address,
+			DefaultText: "0x0000000000000000000000000000000000000000",
+			EnvVars:     []string{envPrefix + strings.ReplaceAll(strings.ToUpper(categoryHealthcheckReth), " ", "_") + "_ARCHIVE_ADDRESS"},
+			Name:        strings.ReplaceAll(categoryHealthcheckReth, " ", "-") + "-archive-address",
+			Usage:       "`address` to query the historical balance (or storage) of",
+		},
+
+		&cli.Uint64Flag{
+			Category:    strings.ToUpper(categoryHealthcheckReth),
+			Destination: &cfg.HealthcheckReth.Archive.BlockFrom,
+			DefaultText: "none",
+			EnvVars:     []string{envPrefix + strings.ReplaceAll(strings.ToUpper(categoryHealthcheckReth), " ", "_") + "_ARCHIVE_BLOCK_FROM"},
+			Name:        strings.ReplaceAll(categoryHealthcheckReth, " ", "-") + "-archive-block-from",
+			Usage:       "historical block `number` (well beyond the pruning horizon) to query the state at, or the start of the range to pick it randomly from (required with archive)",
+		},
+
+		&cli.Uint64Flag{
+			Category:    strings.ToUpper(categoryHealthcheckReth),
+			Destination: &cfg.HealthcheckReth.Archive.BlockTo,
+			DefaultText: "same as block-from",
+			EnvVars:     []string{envPrefix + strings.ReplaceAll(strings.ToUpper(categoryHealthcheckReth), " ", "_") + "_ARCHIVE_BLOCK_TO"},
+			Name:        strings.ReplaceAll(categoryHealthcheckReth, " ", "-") + "-archive-block-to",
+			Usage:       "end `number` (inclusive) of the range to randomly pick the historical block from",
+		},
+
+		&cli.StringFlag{
+			Category:    strings.ToUpper(categoryHealthcheckReth),
+			Destination: &cfg.HealthcheckReth.Archive.StorageSlot,
+			DefaultText: "query the balance",
+			EnvVars:     []string{envPrefix + strings.ReplaceAll(strings.ToUpper(categoryHealthcheckReth), " ", "_") + "_ARCHIVE_STORAGE_SLOT"},
+			Name:        strings.ReplaceAll(categoryHealthcheckReth, " ", "-") + "-archive-storage-slot",
+			Usage:       "storage `slot` to query with eth_getStorageAt instead of eth_getBalance",
+		},
+
 		&cli.StringFlag{
 			Category:    strings.ToUpper(categoryHealthcheckReth),
 			Destination: &cfg.HealthcheckReth.BaseURL,
@@ -115,6 +627,150 @@ func CommandServe(cfg *config.Config) *cli.Command {
 			Name:        strings.ReplaceAll(categoryHealthcheckReth, " ", "-") + "-base-url",
 			Usage:       "base `url` of reth's HTTP-RPC endpoint",
 		},
+
+		&cli.DurationFlag{
+			Category:    strings.ToUpper(categoryHealthcheckReth),
+			Destination: &cfg.HealthcheckReth.CacheCoolOff,
+			DefaultText: "--" + categoryHealthcheck + "-cache-cool-off",
+			EnvVars:     []string{envPrefix + strings.ReplaceAll(strings.ToUpper(categoryHealthcheckReth), " ", "_") + "_CACHE_COOL_OFF"},
+			Name:        strings.ReplaceAll(categoryHealthcheckReth, " ", "-") + "-cache-cool-off",
+			Usage:       "re-use reth's healthcheck results for the specified `duration`",
+		},
+
+		&cli.Uint64Flag{
+			Category:    strings.ToUpper(categoryHealthcheckReth),
+			Destination: &cfg.HealthcheckReth.ChainID,
+			DefaultText: "disabled",
+			EnvVars:     []string{envPrefix + strings.ReplaceAll(strings.ToUpper(categoryHealthcheckReth), " ", "_") + "_CHAIN_ID"},
+			Name:        strings.ReplaceAll(categoryHealthcheckReth, " ", "-") + "-chain-id",
+			Usage:       "report unhealthy if reth's chain id (as per eth_chainId) is different from the expected `id`",
+		},
+
+		&cli.GenericFlag{
+			Category: strings.ToUpper(categoryHealthcheckReth),
+			EnvVars:  []string{envPrefix + strings.ReplaceAll(strings.ToUpper(categoryHealthcheckReth), " ", "_") + "_DEPENDS_ON"},
+			Name:     strings.ReplaceAll(categoryHealthcheckReth, " ", "-") + "-depends-on",
+			Usage:    "skip reth's healthcheck (and report it as such) when the healthcheck of the `source` it depends on fails (can be repeated)",
+			Value:    &cfg.HealthcheckReth.DependsOn,
+		},
+
+		&cli.StringFlag{
+			Category:    strings.ToUpper(categoryHealthcheckReth),
+			Destination: &cfg.HealthcheckReth.Engine.BaseURL,
+			DefaultText: "disabled",
+			EnvVars:     []string{envPrefix + strings.ReplaceAll(strings.ToUpper(categoryHealthcheckReth), " ", "_") + "_ENGINE_BASE_URL"},
+			Name:        strings.ReplaceAll(categoryHealthcheckReth, " ", "-") + "-engine-base-url",
+			Usage:       "base `url` of reth's engine api (authrpc) endpoint",
+		},
+
+		&cli.BoolFlag{
+			Category:    strings.ToUpper(categoryHealthcheckReth),
+			Destination: &cfg.HealthcheckReth.Engine.ClientVersion,
+			EnvVars:     []string{envPrefix + strings.ReplaceAll(strings.ToUpper(categoryHealthcheckReth), " ", "_") + "_ENGINE_CLIENT_VERSION"},
+			Name:        strings.ReplaceAll(categoryHealthcheckReth, " ", "-") + "-engine-client-version",
+			Usage:       "additionally call engine_getClientVersionV1 on reth's engine api",
+		},
+
+		&cli.StringFlag{
+			Category:    strings.ToUpper(categoryHealthcheckReth),
+			Destination: &cfg.HealthcheckReth.Engine.JWTSecretPath,
+			EnvVars:     []string{envPrefix + strings.ReplaceAll(strings.ToUpper(categoryHealthcheckReth), " ", "_") + "_ENGINE_JWT_SECRET"},
+			Name:        strings.ReplaceAll(categoryHealthcheckReth, " ", "-") + "-engine-jwt-secret",
+			Usage:       "`path` to the jwt secret shared with reth's engine api",
+		},
+
+		&cli.StringFlag{
+			Category:    strings.ToUpper(categoryHealthcheckReth),
+			Destination: &cfg.HealthcheckReth.NetVersion,
+			DefaultText: "disabled",
+			EnvVars:     []string{envPrefix + strings.ReplaceAll(strings.ToUpper(categoryHealthcheckReth), " ", "_") + "_NET_VERSION"},
+			Name:        strings.ReplaceAll(categoryHealthcheckReth, " ", "-") + "-net-version",
+			Usage:       "report unhealthy if reth's network id (as per net_version) is different from the expected `id`",
+		},
+
+		&cli.UintFlag{
+			Category:    strings.ToUpper(categoryHealthcheckReth),
+			Destination: &cfg.HealthcheckReth.Retry.Attempts,
+			EnvVars:     []string{envPrefix + strings.ReplaceAll(strings.ToUpper(categoryHealthcheckReth), " ", "_") + "_RETRY_ATTEMPTS"},
+			Name:        strings.ReplaceAll(categoryHealthcheckReth, " ", "-") + "-retry-attempts",
+			Usage:       "maximum `count` of attempts of each request to reth (the requests that failed with connection error, timeout or HTTP 5xx are retried within the healthcheck timeout)",
+			Value:       1,
+		},
+
+		&cli.DurationFlag{
+			Category:    strings.ToUpper(categoryHealthcheckReth),
+			Destination: &cfg.HealthcheckReth.Retry.Backoff,
+			EnvVars:     []string{envPrefix + strings.ReplaceAll(strings.ToUpper(categoryHealthcheckReth), " ", "_") + "_RETRY_BACKOFF"},
+			Name:        strings.ReplaceAll(categoryHealthcheckReth, " ", "-") + "-retry-backoff",
+			Usage:       "initial `duration` to back off for before retrying a request to reth (doubled with each attempt, with jitter)",
+			Value:       100 * time.Millisecond,
+		},
+
+		&cli.GenericFlag{
+			Category: strings.ToUpper(categoryHealthcheckReth),
+			EnvVars:  []string{envPrefix + strings.ReplaceAll(strings.ToUpper(categoryHealthcheckReth), " ", "_") + "_SMOKE_TEST"},
+			Name:     strings.ReplaceAll(categoryHealthcheckReth, " ", "-") + "-smoke-test",
+			Usage:    "JSON-RPC call (as `json` with method, params, path, equals, matches, max_latency and severity) to run against reth and assert upon (can be repeated)",
+			Value:    &cfg.HealthcheckReth.SmokeTests,
+		},
+
+		&cli.DurationFlag{
+			Category:    strings.ToUpper(categoryHealthcheckReth),
+			Destination: &cfg.HealthcheckReth.Timeout,
+			DefaultText: "--" + categoryHealthcheck + "-timeout",
+			EnvVars:     []string{envPrefix + strings.ReplaceAll(strings.ToUpper(categoryHealthcheckReth), " ", "_") + "_TIMEOUT"},
+			Name:        strings.ReplaceAll(categoryHealthcheckReth, " ", "-") + "-timeout",
+			Usage:       "maximum `duration` of reth's healthcheck",
+		},
+
+		&cli.StringFlag{
+			Category:    strings.ToUpper(categoryHealthcheckReth),
+			Destination: &cfg.HealthcheckReth.WebsocketURL,
+			DefaultText: "disabled",
+			EnvVars:     []string{envPrefix + strings.ReplaceAll(strings.ToUpper(categoryHealthcheckReth), " ", "_") + "_WEBSOCKET_URL"},
+			Name:        strings.ReplaceAll(categoryHealthcheckReth, " ", "-") + "-websocket-url",
+			Usage:       "`url` of reth's WS-RPC endpoint to track the latest block via newHeads subscription instead of polling (requires the block age threshold)",
+		},
+	}
+
+	// history
+
+	historyFlags := []cli.Flag{
+		&cli.IntFlag{
+			Category:    strings.ToUpper(categoryHistory),
+			Destination: &cfg.History.Size,
+			EnvVars:     []string{envPrefix + strings.ToUpper(categoryHistory) + "_SIZE"},
+			Name:        categoryHistory + "-size",
+			Usage:       "`count` of the most recent results and transitions to keep per source (for /history)",
+			Value:       1000,
+		},
+
+		&cli.StringFlag{
+			Category:    strings.ToUpper(categoryHistory),
+			Destination: &cfg.History.Store.File,
+			DefaultText: "disabled",
+			EnvVars:     []string{envPrefix + strings.ToUpper(categoryHistory) + "_STORE_FILE"},
+			Name:        categoryHistory + "-store-file",
+			Usage:       "`path` to the jsonl file to persist the transitions and the samples of the sources in (so that the history survives restarts)",
+		},
+
+		&cli.DurationFlag{
+			Category:    strings.ToUpper(categoryHistory),
+			Destination: &cfg.History.Store.Retention,
+			EnvVars:     []string{envPrefix + strings.ToUpper(categoryHistory) + "_STORE_RETENTION"},
+			Name:        categoryHistory + "-store-retention",
+			Usage:       "`duration` for which the events are kept in the history store",
+			Value:       30 * 24 * time.Hour,
+		},
+
+		&cli.DurationFlag{
+			Category:    strings.ToUpper(categoryHistory),
+			Destination: &cfg.History.Store.SampleInterval,
+			EnvVars:     []string{envPrefix + strings.ToUpper(categoryHistory) + "_STORE_SAMPLE_INTERVAL"},
+			Name:        categoryHistory + "-store-sample-interval",
+			Usage:       "`interval` at which the status of the sources is sampled into the history store",
+			Value:       time.Minute,
+		},
 	}
 
 	// http status
@@ -148,6 +804,193 @@ func CommandServe(cfg *config.Config) *cli.Command {
 		},
 	}
 
+	// kubernetes
+
+	kubernetesFlags := []cli.Flag{
+		&cli.StringFlag{
+			Category:    strings.ToUpper(categoryKubernetes),
+			Destination: &cfg.Kubernetes.APIServer,
+			DefaultText: "in-cluster",
+			EnvVars:     []string{envPrefix + strings.ToUpper(categoryKubernetes) + "_API_SERVER"},
+			Name:        categoryKubernetes + "-api-server",
+			Usage:       "`url` of kubernetes api server",
+		},
+
+		&cli.StringFlag{
+			Category:    strings.ToUpper(categoryKubernetes),
+			Destination: &cfg.Kubernetes.CACertFile,
+			EnvVars:     []string{envPrefix + strings.ToUpper(categoryKubernetes) + "_CA_CERT_FILE"},
+			Name:        categoryKubernetes + "-ca-cert-file",
+			Usage:       "`path` to the ca certificate of kubernetes api server",
+			Value:       config.KubernetesServiceAccountDir + "/ca.crt",
+		},
+
+		&cli.StringFlag{
+			Category:    strings.ToUpper(categoryKubernetes),
+			Destination: &cfg.Kubernetes.Namespace,
+			DefaultText: "namespace of the service account",
+			EnvVars:     []string{envPrefix + strings.ToUpper(categoryKubernetes) + "_NAMESPACE"},
+			Name:        categoryKubernetes + "-namespace",
+			Usage:       "kubernetes `namespace` of the pod",
+		},
+
+		&cli.StringFlag{
+			Category:    strings.ToUpper(categoryKubernetes),
+			Destination: &cfg.Kubernetes.PodCondition,
+			DefaultText: "disabled",
+			EnvVars:     []string{envPrefix + strings.ToUpper(categoryKubernetes) + "_POD_CONDITION"},
+			Name:        categoryKubernetes + "-pod-condition",
+			Usage:       "`type` of the custom pod condition to reflect the verdict of the healthchecker in (e.g. 'flashbots.net/node-synced')",
+		},
+
+		&cli.StringFlag{
+			Category:    strings.ToUpper(categoryKubernetes),
+			Destination: &cfg.Kubernetes.PodName,
+			DefaultText: "$POD_NAME or hostname",
+			EnvVars:     []string{envPrefix + strings.ToUpper(categoryKubernetes) + "_POD_NAME"},
+			Name:        categoryKubernetes + "-pod-name",
+			Usage:       "`name` of the pod",
+		},
+
+		&cli.DurationFlag{
+			Category:    strings.ToUpper(categoryKubernetes),
+			Destination: &cfg.Kubernetes.ResyncInterval,
+			EnvVars:     []string{envPrefix + strings.ToUpper(categoryKubernetes) + "_RESYNC_INTERVAL"},
+			Name:        categoryKubernetes + "-resync-interval",
+			Usage:       "`interval` at which the pod condition is re-evaluated even without transitions",
+			Value:       30 * time.Second,
+		},
+
+		&cli.DurationFlag{
+			Category:    strings.ToUpper(categoryKubernetes),
+			Destination: &cfg.Kubernetes.Timeout,
+			EnvVars:     []string{envPrefix + strings.ToUpper(categoryKubernetes) + "_TIMEOUT"},
+			Name:        categoryKubernetes + "-timeout",
+			Usage:       "`timeout` of the requests to kubernetes api server",
+			Value:       5 * time.Second,
+		},
+
+		&cli.StringFlag{
+			Category:    strings.ToUpper(categoryKubernetes),
+			Destination: &cfg.Kubernetes.TokenFile,
+			EnvVars:     []string{envPrefix + strings.ToUpper(categoryKubernetes) + "_TOKEN_FILE"},
+			Name:        categoryKubernetes + "-token-file",
+			Usage:       "`path` to the service-account token to authenticate with kubernetes api server",
+			Value:       config.KubernetesServiceAccountDir + "/token",
+		},
+	}
+
+	// metrics
+
+	metricsFlags := []cli.Flag{
+		&cli.StringFlag{
+			Category:    strings.ToUpper(categoryMetrics),
+			Destination: &cfg.Metrics.OTLP.Endpoint,
+			DefaultText: "disabled",
+			EnvVars:     []string{envPrefix + strings.ToUpper(categoryMetrics) + "_OTLP_ENDPOINT"},
+			Name:        categoryMetrics + "-otlp-endpoint",
+			Usage:       "push metrics to the opentelemetry collector at the specified `url` (or host:port)",
+		},
+
+		&cli.GenericFlag{
+			Category: strings.ToUpper(categoryMetrics),
+			EnvVars:  []string{envPrefix + strings.ToUpper(categoryMetrics) + "_OTLP_HEADER"},
+			Name:     categoryMetrics + "-otlp-header",
+			Usage:    "`key=value` header to send to the opentelemetry collector (can be repeated)",
+			Value:    &cfg.Metrics.OTLP.Headers,
+		},
+
+		&cli.BoolFlag{
+			Category:    strings.ToUpper(categoryMetrics),
+			Destination: &cfg.Metrics.OTLP.Insecure,
+			EnvVars:     []string{envPrefix + strings.ToUpper(categoryMetrics) + "_OTLP_INSECURE"},
+			Name:        categoryMetrics + "-otlp-insecure",
+			Usage:       "connect to the opentelemetry collector without tls",
+		},
+
+		&cli.DurationFlag{
+			Category:    strings.ToUpper(categoryMetrics),
+			Destination: &cfg.Metrics.OTLP.Interval,
+			EnvVars:     []string{envPrefix + strings.ToUpper(categoryMetrics) + "_OTLP_INTERVAL"},
+			Name:        categoryMetrics + "-otlp-interval",
+			Usage:       "push metrics to the opentelemetry collector every `duration`",
+			Value:       30 * time.Second,
+		},
+
+		&cli.StringFlag{
+			Category:    strings.ToUpper(categoryMetrics),
+			Destination: &cfg.Metrics.OTLP.Protocol,
+			EnvVars:     []string{envPrefix + strings.ToUpper(categoryMetrics) + "_OTLP_PROTOCOL"},
+			Name:        categoryMetrics + "-otlp-protocol",
+			Usage:       "`protocol` to push metrics to the opentelemetry collector with (grpc or http)",
+			Value:       config.OTLPProtocolGRPC,
+		},
+
+		&cli.StringFlag{
+			Category:    strings.ToUpper(categoryMetrics),
+			Destination: &cfg.Metrics.OTLP.TLSCACert,
+			DefaultText: "system",
+			EnvVars:     []string{envPrefix + strings.ToUpper(categoryMetrics) + "_OTLP_TLS_CA_CERT"},
+			Name:        categoryMetrics + "-otlp-tls-ca-cert",
+			Usage:       "`path` to the ca certificate(s) to verify the opentelemetry collector with",
+		},
+
+		&cli.StringFlag{
+			Category:    strings.ToUpper(categoryMetrics),
+			Destination: &cfg.Metrics.OTLP.TLSCert,
+			DefaultText: "none",
+			EnvVars:     []string{envPrefix + strings.ToUpper(categoryMetrics) + "_OTLP_TLS_CERT"},
+			Name:        categoryMetrics + "-otlp-tls-cert",
+			Usage:       "`path` to the client certificate to authenticate with at the opentelemetry collector",
+		},
+
+		&cli.BoolFlag{
+			Category:    strings.ToUpper(categoryMetrics),
+			Destination: &cfg.Metrics.OTLP.TLSInsecure,
+			EnvVars:     []string{envPrefix + strings.ToUpper(categoryMetrics) + "_OTLP_TLS_INSECURE_SKIP_VERIFY"},
+			Name:        categoryMetrics + "-otlp-tls-insecure-skip-verify",
+			Usage:       "do not verify the certificate of the opentelemetry collector",
+		},
+
+		&cli.StringFlag{
+			Category:    strings.ToUpper(categoryMetrics),
+			Destination: &cfg.Metrics.OTLP.TLSKey,
+			DefaultText: "none",
+			EnvVars:     []string{envPrefix + strings.ToUpper(categoryMetrics) + "_OTLP_TLS_KEY"},
+			Name:        categoryMetrics + "-otlp-tls-key",
+			Usage:       "`path` to the key of the client certificate",
+		},
+
+		&cli.BoolFlag{
+			Category:    strings.ToUpper(categoryMetrics),
+			Destination: &cfg.Metrics.Prometheus,
+			EnvVars:     []string{envPrefix + strings.ToUpper(categoryMetrics) + "_PROMETHEUS"},
+			Name:        categoryMetrics + "-prometheus",
+			Usage:       "expose prometheus metrics at /metrics",
+			Value:       true,
+		},
+
+		&cli.GenericFlag{
+			Category: strings.ToUpper(categoryMetrics),
+			EnvVars:  []string{envPrefix + strings.ToUpper(categoryMetrics) + "_RESOURCE_ATTRIBUTE"},
+			Name:     categoryMetrics + "-resource-attribute",
+			Usage:    "`key=value` attribute (e.g. service.instance.id) to attach to the exported metrics and traces (can be repeated)",
+			Value:    &cfg.Metrics.ResourceAttributes,
+		},
+	}
+
+	// policy
+
+	policyFlags := []cli.Flag{
+		&cli.GenericFlag{
+			Category: strings.ToUpper(categoryPolicy),
+			EnvVars:  []string{envPrefix + strings.ToUpper(categoryPolicy)},
+			Name:     categoryPolicy,
+			Usage:    "`rule` in the form of '[<path>] <status>: <expression>' that overrides the default verdict at the endpoint (can be repeated, first match wins)",
+			Value:    &cfg.Policy,
+		},
+	}
+
 	// server
 
 	serverFlags := []cli.Flag{
@@ -161,18 +1004,181 @@ func CommandServe(cfg *config.Config) *cli.Command {
 		},
 	}
 
+	// slo
+
+	sloFlags := []cli.Flag{
+		&cli.GenericFlag{
+			Category:    strings.ToUpper(categorySLO),
+			DefaultText: "5m,30m,1h,6h",
+			EnvVars:     []string{envPrefix + strings.ToUpper(categorySLO) + "_BURN_RATE_WINDOWS"},
+			Name:        categorySLO + "-burn-rate-windows",
+			Usage:       "comma-separated `list` of the windows to report the burn rates of the error budgets over",
+			Value:       &cfg.SLO.BurnRateWindows,
+		},
+
+		&cli.GenericFlag{
+			Category: strings.ToUpper(categorySLO),
+			EnvVars:  []string{envPrefix + strings.ToUpper(categorySLO) + "_OBJECTIVE"},
+			Name:     categorySLO + "-objective",
+			Usage:    "service-level `objective` as target availability over the window, e.g. '30d=99.9%' (can be repeated)",
+			Value:    &cfg.SLO.Objectives,
+		},
+	}
+
+	// tracing
+
+	tracingFlags := []cli.Flag{
+		&cli.StringFlag{
+			Category:    strings.ToUpper(categoryTracing),
+			Destination: &cfg.Tracing.OTLP.Endpoint,
+			DefaultText: "disabled",
+			EnvVars:     []string{envPrefix + strings.ToUpper(categoryTracing) + "_OTLP_ENDPOINT"},
+			Name:        categoryTracing + "-otlp-endpoint",
+			Usage:       "push traces to the opentelemetry collector at the specified `url` (or host:port)",
+		},
+
+		&cli.GenericFlag{
+			Category: strings.ToUpper(categoryTracing),
+			EnvVars:  []string{envPrefix + strings.ToUpper(categoryTracing) + "_OTLP_HEADER"},
+			Name:     categoryTracing + "-otlp-header",
+			Usage:    "`key=value` header to send to the opentelemetry collector (can be repeated)",
+			Value:    &cfg.Tracing.OTLP.Headers,
+		},
+
+		&cli.BoolFlag{
+			Category:    strings.ToUpper(categoryTracing),
+			Destination: &cfg.Tracing.OTLP.Insecure,
+			EnvVars:     []string{envPrefix + strings.ToUpper(categoryTracing) + "_OTLP_INSECURE"},
+			Name:        categoryTracing + "-otlp-insecure",
+			Usage:       "connect to the opentelemetry collector without tls",
+		},
+
+		&cli.StringFlag{
+			Category:    strings.ToUpper(categoryTracing),
+			Destination: &cfg.Tracing.OTLP.Protocol,
+			EnvVars:     []string{envPrefix + strings.ToUpper(categoryTracing) + "_OTLP_PROTOCOL"},
+			Name:        categoryTracing + "-otlp-protocol",
+			Usage:       "`protocol` to push traces to the opentelemetry collector with (grpc or http)",
+			Value:       config.OTLPProtocolGRPC,
+		},
+
+		&cli.StringFlag{
+			Category:    strings.ToUpper(categoryTracing),
+			Destination: &cfg.Tracing.OTLP.TLSCACert,
+			DefaultText: "system",
+			EnvVars:     []string{envPrefix + strings.ToUpper(categoryTracing) + "_OTLP_TLS_CA_CERT"},
+			Name:        categoryTracing + "-otlp-tls-ca-cert",
+			Usage:       "`path` to the ca certificate(s) to verify the opentelemetry collector with",
+		},
+
+		&cli.StringFlag{
+			Category:    strings.ToUpper(categoryTracing),
+			Destination: &cfg.Tracing.OTLP.TLSCert,
+			DefaultText: "none",
+			EnvVars:     []string{envPrefix + strings.ToUpper(categoryTracing) + "_OTLP_TLS_CERT"},
+			Name:        categoryTracing + "-otlp-tls-cert",
+			Usage:       "`path` to the client certificate to authenticate with at the opentelemetry collector",
+		},
+
+		&cli.BoolFlag{
+			Category:    strings.ToUpper(categoryTracing),
+			Destination: &cfg.Tracing.OTLP.TLSInsecure,
+			EnvVars:     []string{envPrefix + strings.ToUpper(categoryTracing) + "_OTLP_TLS_INSECURE_SKIP_VERIFY"},
+			Name:        categoryTracing + "-otlp-tls-insecure-skip-verify",
+			Usage:       "do not verify the certificate of the opentelemetry collector",
+		},
+
+		&cli.StringFlag{
+			Category:    strings.ToUpper(categoryTracing),
+			Destination: &cfg.Tracing.OTLP.TLSKey,
+			DefaultText: "none",
+			EnvVars:     []string{envPrefix + strings.ToUpper(categoryTracing) + "_OTLP_TLS_KEY"},
+			Name:        categoryTracing + "-otlp-tls-key",
+			Usage:       "`path` to the key of the client certificate",
+		},
+
+		&cli.Float64Flag{
+			Category:    strings.ToUpper(categoryTracing),
+			Destination: &cfg.Tracing.SampleRatio,
+			EnvVars:     []string{envPrefix + strings.ToUpper(categoryTracing) + "_SAMPLE_RATIO"},
+			Name:        categoryTracing + "-sample-ratio",
+			Usage:       "`ratio` of the healthchecks to trace (unless the caller's trace is sampled already)",
+			Value:       1,
+		},
+	}
+
+	// webhook
+
+	webhookFlags := []cli.Flag{
+		&cli.GenericFlag{
+			Category: strings.ToUpper(categoryWebhook),
+			EnvVars:  []string{envPrefix + strings.ToUpper(categoryWebhook)},
+			Name:     categoryWebhook,
+			Usage:    "`url` (or json with url, headers, min_severity and template) to post the health transitions to (can be repeated)",
+			Value:    &cfg.Webhook.Endpoints,
+		},
+
+		&cli.DurationFlag{
+			Category:    strings.ToUpper(categoryWebhook),
+			Destination: &cfg.Webhook.DedupWindow,
+			DefaultText: "disabled",
+			EnvVars:     []string{envPrefix + strings.ToUpper(categoryWebhook) + "_DEDUP_WINDOW"},
+			Name:        categoryWebhook + "-dedup-window",
+			Usage:       "suppress repeated notifications about the same source reaching the same status within `duration`",
+			Value:       0,
+		},
+
+		&cli.IntFlag{
+			Category:    strings.ToUpper(categoryWebhook),
+			Destination: &cfg.Webhook.Retries,
+			EnvVars:     []string{envPrefix + strings.ToUpper(categoryWebhook) + "_RETRIES"},
+			Name:        categoryWebhook + "-retries",
+			Usage:       "`count` of retries of failed webhook deliveries",
+			Value:       3,
+		},
+
+		&cli.DurationFlag{
+			Category:    strings.ToUpper(categoryWebhook),
+			Destination: &cfg.Webhook.RetryDelay,
+			EnvVars:     []string{envPrefix + strings.ToUpper(categoryWebhook) + "_RETRY_DELAY"},
+			Name:        categoryWebhook + "-retry-delay",
+			Usage:       "initial `duration` to wait before retrying the webhook delivery (doubled on every retry)",
+			Value:       time.Second,
+		},
+
+		&cli.DurationFlag{
+			Category:    strings.ToUpper(categoryWebhook),
+			Destination: &cfg.Webhook.Timeout,
+			EnvVars:     []string{envPrefix + strings.ToUpper(categoryWebhook) + "_TIMEOUT"},
+			Name:        categoryWebhook + "-timeout",
+			Usage:       "maximum `duration` of a single webhook delivery attempt",
+			Value:       5 * time.Second,
+		},
+	}
+
 	return &cli.Command{
 		Name:  "serve",
 		Usage: "run node-healthchecker server",
 
 		Flags: slices.Concat(
+			consulFlags,
+			drainFlags,
+			grpcFlags,
+			haproxyAgentFlags,
 			healthcheckFlags,
 			healthcheckGethFlags,
 			healthcheckLighthouseFlags,
 			healthcheckOpNodeFlags,
 			healthcheckRethFlags,
+			historyFlags,
 			httpStatusFlags,
+			kubernetesFlags,
+			metricsFlags,
+			policyFlags,
 			serverFlags,
+			sloFlags,
+			tracingFlags,
+			webhookFlags,
 		),
 
 		Before: func(ctx *cli.Context) error {
@@ -1,10 +1,25 @@
 package config
 
+import (
+	"fmt"
+	"time"
+)
+
 type Config struct {
 	Log    Log    `yaml:"log"`
 	Server Server `yaml:"server"`
 
-	HttpStatus HttpStatus `yaml:"http_status"`
+	Consul       Consul       `yaml:"consul"`
+	Drain        Drain        `yaml:"drain"`
+	GRPC         GRPC         `yaml:"grpc"`
+	HAProxyAgent HAProxyAgent `yaml:"haproxy_agent"`
+	History      History      `yaml:"history"`
+	HttpStatus   HttpStatus   `yaml:"http_status"`
+	Kubernetes   Kubernetes   `yaml:"kubernetes"`
+	Metrics      Metrics      `yaml:"metrics"`
+	Policy       PolicyRules  `yaml:"policy"`
+	SLO          SLO          `yaml:"slo"`
+	Tracing      Tracing      `yaml:"tracing"`
 
 	Healthcheck Healthcheck `yaml:"healthcheck"`
 
@@ -12,6 +27,8 @@ type Config struct {
 	HealthcheckLighthouse HealthcheckLighthouse `yaml:"healthcheck_lighthouse"`
 	HealthcheckOpNode     HealthcheckOpNode     `yaml:"healthcheck_op_node"`
 	HealthcheckReth       HealthcheckReth       `yaml:"healthcheck_reth"`
+
+	Webhook Webhook `yaml:"webhook"`
 }
 
 func (c *Config) Preprocess() error {
@@ -24,14 +41,87 @@ func (c *Config) Preprocess() error {
 		c.HealthcheckReth.BlockAgeThreshold = c.Healthcheck.BlockAgeThreshold
 	}
 
+	// the sources that don't override the timeout and/or the cool-off inherit
+	// them from the healthcheck section
+
+	for _, timeout := range []*time.Duration{
+		&c.HealthcheckGeth.Timeout,
+		&c.HealthcheckLighthouse.Timeout,
+		&c.HealthcheckOpNode.Timeout,
+		&c.HealthcheckReth.Timeout,
+	} {
+		if *timeout == 0 {
+			*timeout = c.Healthcheck.Timeout
+		}
+	}
+
+	for _, coolOff := range []*time.Duration{
+		&c.HealthcheckGeth.CacheCoolOff,
+		&c.HealthcheckLighthouse.CacheCoolOff,
+		&c.HealthcheckOpNode.CacheCoolOff,
+		&c.HealthcheckReth.CacheCoolOff,
+	} {
+		if *coolOff == 0 {
+			*coolOff = c.Healthcheck.CacheCoolOff
+		}
+	}
+
 	errs = append(errs, c.Log.Preprocess())
 	errs = append(errs, c.Server.Preprocess())
+	errs = append(errs, c.Consul.Preprocess())
+	errs = append(errs, c.Drain.Preprocess())
+	errs = append(errs, c.GRPC.Preprocess())
+	errs = append(errs, c.HAProxyAgent.Preprocess())
+	errs = append(errs, c.History.Preprocess())
 	errs = append(errs, c.HttpStatus.Preprocess())
+	errs = append(errs, c.Kubernetes.Preprocess())
+	errs = append(errs, c.Metrics.Preprocess())
 	errs = append(errs, c.Healthcheck.Preprocess())
 	errs = append(errs, c.HealthcheckGeth.Preprocess())
 	errs = append(errs, c.HealthcheckLighthouse.Preprocess())
 	errs = append(errs, c.HealthcheckOpNode.Preprocess())
 	errs = append(errs, c.HealthcheckReth.Preprocess())
+	errs = append(errs, c.preprocessDependencies())
+	errs = append(errs, c.Policy.Preprocess(c.Sources()))
+	errs = append(errs, c.SLO.Preprocess())
+	errs = append(errs, c.preprocessSLOWindows())
+	errs = append(errs, c.Tracing.Preprocess())
+	errs = append(errs, c.Webhook.Preprocess())
 
 	return flatten(errs)
 }
+
+// preprocessSLOWindows checks that the history store (if any) retains the
+// events for long enough to cover the slo windows.
+func (c *Config) preprocessSLOWindows() error {
+	if c.History.Store.File == "" {
+		return nil
+	}
+	errs := make([]error, 0)
+	for _, objective := range c.SLO.Objectives {
+		if objective.Duration > c.History.Store.Retention {
+			errs = append(errs, fmt.Errorf("slo window %s exceeds the retention of the history store (%s)",
+				objective.Window, c.History.Store.Retention,
+			))
+		}
+	}
+	return flatten(errs)
+}
+
+// Sources returns the names of the enabled healthcheck sources.
+func (c *Config) Sources() []string {
+	sources := make([]string, 0)
+	if c.HealthcheckGeth.BaseURL != "" {
+		sources = append(sources, "geth")
+	}
+	if c.HealthcheckLighthouse.BaseURL != "" {
+		sources = append(sources, "lighthouse")
+	}
+	if c.HealthcheckOpNode.BaseURL != "" {
+		sources = append(sources, "op-node")
+	}
+	if c.HealthcheckReth.BaseURL != "" {
+		sources = append(sources, "reth")
+	}
+	return sources
+}
@@ -0,0 +1,65 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"time"
+)
+
+// Consul is the configuration of the registration of the node's service in
+// the local consul agent.
+type Consul struct {
+	Address                        string        `yaml:"address"`
+	CheckTTL                       time.Duration `yaml:"check_ttl"`
+	DeregisterCriticalServiceAfter time.Duration `yaml:"deregister_critical_service_after"`
+	ServiceAddress                 string        `yaml:"service_address"`
+	ServiceID                      string        `yaml:"service_id"`
+	ServiceName                    string        `yaml:"service_name"`
+	ServicePort                    int           `yaml:"service_port"`
+	ServiceTags                    StringList    `yaml:"service_tags"`
+	Timeout                        time.Duration `yaml:"timeout"`
+	Token                          string        `yaml:"token" secret:"true"`
+}
+
+func (c *Consul) Preprocess() error {
+	if c.Address == "" {
+		return nil
+	}
+
+	if _, err := url.Parse(c.Address); err != nil {
+		return fmt.Errorf("invalid consul address: %w",
+			err,
+		)
+	}
+	if c.ServiceName == "" {
+		return errors.New("consul service name is required")
+	}
+	if c.ServiceID == "" {
+		hostname, err := os.Hostname()
+		if err != nil {
+			return fmt.Errorf("failed to derive consul service id: %w",
+				err,
+			)
+		}
+		c.ServiceID = c.ServiceName + "-" + hostname
+	}
+	if c.ServicePort < 0 || c.ServicePort > 65535 {
+		return fmt.Errorf("invalid consul service port: %d",
+			c.ServicePort,
+		)
+	}
+	if c.CheckTTL <= 0 {
+		return fmt.Errorf("invalid consul check ttl: %s",
+			c.CheckTTL,
+		)
+	}
+	if c.DeregisterCriticalServiceAfter < 0 {
+		return fmt.Errorf("invalid consul deregister-critical-service-after: %s",
+			c.DeregisterCriticalServiceAfter,
+		)
+	}
+
+	return nil
+}
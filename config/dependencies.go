@@ -0,0 +1,94 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Dependencies returns the dependencies of the enabled healthcheck sources.
+func (c *Config) Dependencies() map[string][]string {
+	deps := make(map[string][]string)
+	if c.HealthcheckGeth.BaseURL != "" {
+		deps["geth"] = c.HealthcheckGeth.DependsOn
+	}
+	if c.HealthcheckLighthouse.BaseURL != "" {
+		deps["lighthouse"] = c.HealthcheckLighthouse.DependsOn
+	}
+	if c.HealthcheckOpNode.BaseURL != "" {
+		deps["op-node"] = c.HealthcheckOpNode.DependsOn
+	}
+	if c.HealthcheckReth.BaseURL != "" {
+		deps["reth"] = c.HealthcheckReth.DependsOn
+	}
+	return deps
+}
+
+func (c *Config) preprocessDependencies() error {
+	deps := c.Dependencies()
+	errs := make([]error, 0)
+
+	for source, dependencies := range deps {
+		for _, dep := range dependencies {
+			if dep == source {
+				errs = append(errs, fmt.Errorf("%s can not depend on itself",
+					source,
+				))
+				continue
+			}
+			if _, enabled := deps[dep]; !enabled {
+				errs = append(errs, fmt.Errorf("%s depends on unknown or disabled source: %s",
+					source, dep,
+				))
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return flatten(errs)
+	}
+
+	// detect cycles
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make(map[string]int, len(deps))
+	path := make([]string, 0, len(deps))
+
+	var visit func(source string) error
+	visit = func(source string) error {
+		switch state[source] {
+		case visiting:
+			return fmt.Errorf("circular dependency: %s -> %s",
+				strings.Join(path, " -> "), source,
+			)
+		case visited:
+			return nil
+		}
+		state[source] = visiting
+		path = append(path, source)
+		for _, dep := range deps[source] {
+			if err := visit(dep); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		state[source] = visited
+		return nil
+	}
+
+	sources := make([]string, 0, len(deps))
+	for source := range deps {
+		sources = append(sources, source)
+	}
+	slices.Sort(sources)
+	for _, source := range sources {
+		if err := visit(source); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
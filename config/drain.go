@@ -0,0 +1,21 @@
+package config
+
+import (
+	"fmt"
+	"net/http"
+)
+
+type Drain struct {
+	AdminToken string `yaml:"admin_token" secret:"true"`
+	HttpStatus int    `yaml:"http_status"`
+	StateFile  string `yaml:"state_file"`
+}
+
+func (c *Drain) Preprocess() error {
+	if http.StatusText(c.HttpStatus) == "" {
+		return fmt.Errorf("invalid drain http status: %d",
+			c.HttpStatus,
+		)
+	}
+	return nil
+}
@@ -0,0 +1,9 @@
+package config
+
+type GRPC struct {
+	ListenAddress string `yaml:"listen_address"`
+}
+
+func (c *GRPC) Preprocess() error {
+	return nil
+}
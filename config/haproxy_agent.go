@@ -0,0 +1,55 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// HAProxyAgent is the configuration of the listener that speaks haproxy's
+// agent-check protocol.
+type HAProxyAgent struct {
+	ListenAddress string `yaml:"listen_address"`
+	WeightOk      string `yaml:"weight_ok"`
+	WeightWarning string `yaml:"weight_warning"`
+}
+
+func (c *HAProxyAgent) Preprocess() error {
+	if c.ListenAddress == "" {
+		return nil
+	}
+	if err := validateWeight(c.WeightOk); err != nil {
+		return fmt.Errorf("invalid haproxy agent weight for ok state: %w",
+			err,
+		)
+	}
+	if err := validateWeight(c.WeightWarning); err != nil {
+		return fmt.Errorf("invalid haproxy agent weight for warning state: %w",
+			err,
+		)
+	}
+	return nil
+}
+
+// validateWeight checks that the weight is a percentage within [0%, 256%]
+// (as accepted by haproxy).
+func validateWeight(weight string) error {
+	num, found := strings.CutSuffix(weight, "%")
+	if !found {
+		return fmt.Errorf("must be a percentage (e.g. '50%%'): %s",
+			weight,
+		)
+	}
+	pct, err := strconv.ParseUint(num, 10, 64)
+	if err != nil {
+		return fmt.Errorf("must be a percentage (e.g. '50%%'): %s",
+			weight,
+		)
+	}
+	if pct > 256 {
+		return fmt.Errorf("must not exceed 256%%: %s",
+			weight,
+		)
+	}
+	return nil
+}
@@ -5,7 +5,13 @@ import "time"
 type Healthcheck struct {
 	BlockAgeThreshold time.Duration `yaml:"block_age_threshold"`
 	CacheCoolOff      time.Duration `yaml:"cache_cool_off"`
+	Interval          time.Duration `yaml:"interval"`
 	Timeout           time.Duration `yaml:"timeout"`
+
+	// StaleWhileRevalidate is for how long after the cool-off the stale
+	// results are still served (while the healthcheck is refreshed in
+	// background).
+	StaleWhileRevalidate time.Duration `yaml:"stale_while_revalidate"`
 }
 
 func (c *Healthcheck) Preprocess() error {
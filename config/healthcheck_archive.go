@@ -0,0 +1,51 @@
+package config
+
+import (
+	"fmt"
+)
+
+type HealthcheckArchive struct {
+	Enabled     bool   `yaml:"enabled"`
+	Address     string `yaml:"address"`
+	BlockFrom   uint64 `yaml:"block_from"`
+	BlockTo     uint64 `yaml:"block_to"`
+	StorageSlot string `yaml:"storage_slot"`
+}
+
+func (c *HealthcheckArchive) Preprocess() error {
+	if !c.Enabled {
+		return nil
+	}
+
+	if c.Address == "" {
+		c.Address = "0x0000000000000000000000000000000000000000"
+	}
+	if err := validateHex(c.Address, 20); err != nil {
+		return fmt.Errorf("invalid archive address: %w",
+			err,
+		)
+	}
+
+	if c.StorageSlot != "" {
+		if err := validateHexQuantity(c.StorageSlot, 32); err != nil {
+			return fmt.Errorf("invalid archive storage slot: %w",
+				err,
+			)
+		}
+	}
+
+	if c.BlockFrom == 0 {
+		// the genesis state is kept by the pruned nodes too
+		return fmt.Errorf("archive block is required (and must be after the genesis)")
+	}
+	if c.BlockTo == 0 {
+		c.BlockTo = c.BlockFrom
+	}
+	if c.BlockTo < c.BlockFrom {
+		return fmt.Errorf("invalid archive block range: %d > %d",
+			c.BlockFrom, c.BlockTo,
+		)
+	}
+
+	return nil
+}
@@ -0,0 +1,55 @@
+package config
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+)
+
+type HealthcheckEngine struct {
+	BaseURL       string `yaml:"base_url" secret:"url"`
+	ClientVersion bool   `yaml:"client_version"`
+	JWTSecretPath string `yaml:"jwt_secret_path"`
+
+	JWTSecret []byte `yaml:"-"`
+}
+
+func (c *HealthcheckEngine) Preprocess() error {
+	if c.BaseURL == "" {
+		return nil
+	}
+
+	if _, err := url.Parse(c.BaseURL); err != nil {
+		return fmt.Errorf("invalid engine api base url: %w",
+			err,
+		)
+	}
+
+	if c.JWTSecretPath == "" {
+		return errors.New("engine api jwt secret is required")
+	}
+
+	raw, err := os.ReadFile(c.JWTSecretPath)
+	if err != nil {
+		return fmt.Errorf("failed to read engine api jwt secret: %w",
+			err,
+		)
+	}
+	secret, err := hex.DecodeString(strings.TrimPrefix(strings.TrimSpace(string(raw)), "0x"))
+	if err != nil {
+		return fmt.Errorf("invalid engine api jwt secret: %w",
+			err,
+		)
+	}
+	if len(secret) != 32 {
+		return fmt.Errorf("invalid engine api jwt secret: expected 32 bytes, got %d",
+			len(secret),
+		)
+	}
+	c.JWTSecret = secret
+
+	return nil
+}
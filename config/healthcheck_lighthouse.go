@@ -7,8 +7,15 @@ import (
 )
 
 type HealthcheckLighthouse struct {
-	BaseURL           string        `yaml:"base_url"`
-	BlockAgeThreshold time.Duration `yaml:"-"`
+	BaseURL               string        `yaml:"base_url" secret:"url"`
+	DependsOn             StringList    `yaml:"depends_on"`
+	BlockAgeThreshold     time.Duration `yaml:"-"`
+	CacheCoolOff          time.Duration `yaml:"cache_cool_off"`
+	GenesisForkVersion    string        `yaml:"genesis_fork_version"`
+	GenesisValidatorsRoot string        `yaml:"genesis_validators_root"`
+	Timeout               time.Duration `yaml:"timeout"`
+
+	Retry HealthcheckRetry `yaml:"retry"`
 }
 
 func (c *HealthcheckLighthouse) Preprocess() error {
@@ -19,5 +26,24 @@ func (c *HealthcheckLighthouse) Preprocess() error {
 			)
 		}
 	}
+	if c.GenesisForkVersion != "" {
+		if err := validateHex(c.GenesisForkVersion, 4); err != nil {
+			return fmt.Errorf("invalid lighthouse genesis fork version: %w",
+				err,
+			)
+		}
+	}
+	if c.GenesisValidatorsRoot != "" {
+		if err := validateHex(c.GenesisValidatorsRoot, 32); err != nil {
+			return fmt.Errorf("invalid lighthouse genesis validators root: %w",
+				err,
+			)
+		}
+	}
+	if err := c.Retry.Preprocess(); err != nil {
+		return fmt.Errorf("lighthouse: %w",
+			err,
+		)
+	}
 	return nil
 }
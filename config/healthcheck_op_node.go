@@ -7,9 +7,16 @@ import (
 )
 
 type HealthcheckOpNode struct {
-	BaseURL              string        `yaml:"base_url"`
+	BaseURL              string        `yaml:"base_url" secret:"url"`
+	DependsOn            StringList    `yaml:"depends_on"`
 	BlockAgeThreshold    time.Duration `yaml:"-"`
+	CacheCoolOff         time.Duration `yaml:"cache_cool_off"`
+	ChainID              uint64        `yaml:"chain_id"`
 	ConfirmationDistance uint64        `yaml:"confirmation_distance"`
+	L1ChainID            uint64        `yaml:"l1_chain_id"`
+	Timeout              time.Duration `yaml:"timeout"`
+
+	Retry HealthcheckRetry `yaml:"retry"`
 }
 
 func (c *HealthcheckOpNode) Preprocess() error {
@@ -20,5 +27,10 @@ func (c *HealthcheckOpNode) Preprocess() error {
 			)
 		}
 	}
+	if err := c.Retry.Preprocess(); err != nil {
+		return fmt.Errorf("op-node: %w",
+			err,
+		)
+	}
 	return nil
 }
@@ -3,12 +3,24 @@ package config
 import (
 	"fmt"
 	"net/url"
+	"strconv"
 	"time"
 )
 
 type HealthcheckReth struct {
-	BaseURL           string        `yaml:"base_url"`
+	BaseURL           string        `yaml:"base_url" secret:"url"`
+	DependsOn         StringList    `yaml:"depends_on"`
 	BlockAgeThreshold time.Duration `yaml:"-"`
+	CacheCoolOff      time.Duration `yaml:"cache_cool_off"`
+	ChainID           uint64        `yaml:"chain_id"`
+	NetVersion        string        `yaml:"net_version"`
+	WebsocketURL      string        `yaml:"websocket_url" secret:"url"`
+	Timeout           time.Duration `yaml:"timeout"`
+
+	Archive    HealthcheckArchive    `yaml:"archive"`
+	Engine     HealthcheckEngine     `yaml:"engine"`
+	Retry      HealthcheckRetry      `yaml:"retry"`
+	SmokeTests HealthcheckSmokeTests `yaml:"smoke_tests"`
 }
 
 func (c *HealthcheckReth) Preprocess() error {
@@ -19,5 +31,48 @@ func (c *HealthcheckReth) Preprocess() error {
 			)
 		}
 	}
+	if c.WebsocketURL != "" {
+		u, err := url.Parse(c.WebsocketURL)
+		if err != nil {
+			return fmt.Errorf("invalid reth websocket url: %w",
+				err,
+			)
+		}
+		if u.Scheme != "ws" && u.Scheme != "wss" {
+			return fmt.Errorf("invalid reth websocket url: unsupported scheme '%s'",
+				u.Scheme,
+			)
+		}
+		if c.BlockAgeThreshold == 0 {
+			return fmt.Errorf("reth websocket url requires the block age threshold (the stalled heads can not be detected otherwise)")
+		}
+	}
+	if c.NetVersion != "" {
+		if _, err := strconv.ParseUint(c.NetVersion, 10, 64); err != nil {
+			return fmt.Errorf("invalid reth net version: %w",
+				err,
+			)
+		}
+	}
+	if err := c.Archive.Preprocess(); err != nil {
+		return fmt.Errorf("reth: %w",
+			err,
+		)
+	}
+	if err := c.Engine.Preprocess(); err != nil {
+		return fmt.Errorf("reth: %w",
+			err,
+		)
+	}
+	if err := c.Retry.Preprocess(); err != nil {
+		return fmt.Errorf("reth: %w",
+			err,
+		)
+	}
+	if err := c.SmokeTests.Preprocess(); err != nil {
+		return fmt.Errorf("reth: %w",
+			err,
+		)
+	}
 	return nil
 }
@@ -0,0 +1,23 @@
+package config
+
+import (
+	"errors"
+	"time"
+)
+
+// HealthcheckRetry is the policy of retrying the requests of a healthcheck
+// that failed transiently (connection refused or reset, timeout, HTTP 5xx).
+type HealthcheckRetry struct {
+	Attempts uint          `yaml:"attempts"`
+	Backoff  time.Duration `yaml:"backoff"`
+}
+
+func (c *HealthcheckRetry) Preprocess() error {
+	if c.Attempts == 0 {
+		c.Attempts = 1
+	}
+	if c.Attempts > 1 && c.Backoff <= 0 {
+		return errors.New("retry backoff must be positive")
+	}
+	return nil
+}
@@ -0,0 +1,155 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/flashbots/node-healthchecker/utils"
+)
+
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// HealthcheckSmokeTest is a JSON-RPC call with an assertion on its outcome.
+//
+// On the command line it is specified as JSON, for example:
+//
+//	{"method":"eth_getBalance","params":["0x0000000000000000000000000000000000000000","latest"],"path":"$.result","matches":"^0x","max_latency":"250ms","severity":"warning"}
+type HealthcheckSmokeTest struct {
+	Name       string          `yaml:"name"        json:"name"`
+	Method     string          `yaml:"method"      json:"method"`
+	Params     json.RawMessage `yaml:"params"      json:"params"`
+	Path       string          `yaml:"path"        json:"path"`
+	Equals     json.RawMessage `yaml:"equals"      json:"equals"`
+	Matches    string          `yaml:"matches"     json:"matches"`
+	MaxLatency time.Duration   `yaml:"max_latency" json:"-"`
+	Severity   string          `yaml:"severity"    json:"severity"`
+
+	JSONPath utils.JSONPath `yaml:"-" json:"-"`
+	Regexp   *regexp.Regexp `yaml:"-" json:"-"`
+}
+
+func (c *HealthcheckSmokeTest) UnmarshalJSON(data []byte) error {
+	type alias HealthcheckSmokeTest
+	raw := struct {
+		*alias
+		MaxLatency string `json:"max_latency"`
+	}{
+		alias: (*alias)(c),
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	if raw.MaxLatency != "" {
+		latency, err := time.ParseDuration(raw.MaxLatency)
+		if err != nil {
+			return fmt.Errorf("invalid max latency: %w",
+				err,
+			)
+		}
+		c.MaxLatency = latency
+	}
+	return nil
+}
+
+func (c *HealthcheckSmokeTest) Preprocess() error {
+	if c.Method == "" {
+		return errors.New("smoke test method is required")
+	}
+	if c.Name == "" {
+		c.Name = c.Method
+	}
+
+	if len(c.Params) == 0 {
+		c.Params = json.RawMessage("[]")
+	}
+	if !json.Valid(c.Params) {
+		return fmt.Errorf("invalid params of smoke test '%s': %s",
+			c.Name, string(c.Params),
+		)
+	}
+
+	if c.Path == "" {
+		c.Path = "$.result"
+	}
+	path, err := utils.ParseJSONPath(c.Path)
+	if err != nil {
+		return fmt.Errorf("invalid path of smoke test '%s': %w",
+			c.Name, err,
+		)
+	}
+	c.JSONPath = path
+
+	if len(c.Equals) > 0 && !json.Valid(c.Equals) {
+		return fmt.Errorf("invalid expected value of smoke test '%s': %s",
+			c.Name, string(c.Equals),
+		)
+	}
+
+	if c.Matches != "" {
+		re, err := regexp.Compile(c.Matches)
+		if err != nil {
+			return fmt.Errorf("invalid regexp of smoke test '%s': %w",
+				c.Name, err,
+			)
+		}
+		c.Regexp = re
+	}
+
+	switch strings.ToLower(c.Severity) {
+	case "", SeverityError:
+		c.Severity = SeverityError
+	case SeverityWarning:
+		c.Severity = SeverityWarning
+	default:
+		return fmt.Errorf("invalid severity of smoke test '%s': %s",
+			c.Name, c.Severity,
+		)
+	}
+
+	return nil
+}
+
+// HealthcheckSmokeTests implements `flag.Value` so that smoke tests can be
+// specified by repeating the command-line flag.
+type HealthcheckSmokeTests []*HealthcheckSmokeTest
+
+func (c *HealthcheckSmokeTests) Set(value string) error {
+	test := &HealthcheckSmokeTest{}
+	if err := json.Unmarshal([]byte(value), test); err != nil {
+		return fmt.Errorf("invalid smoke test '%s': %w",
+			value, err,
+		)
+	}
+	*c = append(*c, test)
+	return nil
+}
+
+func (c *HealthcheckSmokeTests) String() string {
+	if c == nil {
+		return ""
+	}
+	names := make([]string, 0, len(*c))
+	for _, test := range *c {
+		if test.Name != "" {
+			names = append(names, test.Name)
+		} else {
+			names = append(names, test.Method)
+		}
+	}
+	return strings.Join(names, ", ")
+}
+
+func (c HealthcheckSmokeTests) Preprocess() error {
+	errs := make([]error, 0, len(c))
+	for _, test := range c {
+		errs = append(errs, test.Preprocess())
+	}
+	return flatten(errs)
+}
@@ -0,0 +1,45 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+type History struct {
+	Size  int          `yaml:"size"`
+	Store HistoryStore `yaml:"store"`
+}
+
+// HistoryStore is the configuration of the on-disk (jsonl) store of the
+// transitions and periodic samples of the sources, that survives restarts.
+type HistoryStore struct {
+	File           string        `yaml:"file"`
+	Retention      time.Duration `yaml:"retention"`
+	SampleInterval time.Duration `yaml:"sample_interval"`
+}
+
+func (c *History) Preprocess() error {
+	if c.Size < 1 {
+		return fmt.Errorf("invalid history size: %d",
+			c.Size,
+		)
+	}
+	return c.Store.Preprocess()
+}
+
+func (c *HistoryStore) Preprocess() error {
+	if c.File == "" {
+		return nil
+	}
+	if c.Retention <= 0 {
+		return fmt.Errorf("invalid history store retention: %s",
+			c.Retention,
+		)
+	}
+	if c.SampleInterval <= 0 {
+		return fmt.Errorf("invalid history store sample interval: %s",
+			c.SampleInterval,
+		)
+	}
+	return nil
+}
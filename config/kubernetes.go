@@ -0,0 +1,125 @@
+package config
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	KubernetesServiceAccountDir = "/var/run/secrets/kubernetes.io/serviceaccount"
+)
+
+var (
+	errKubernetesInvalidCACert = errors.New("no valid certificates found in the ca file")
+)
+
+// Kubernetes is the configuration of the in-cluster mode, in which the
+// healthchecker reflects its verdict in a custom condition of its own pod
+// (so that it can be used in the pod's `readinessGates`).
+type Kubernetes struct {
+	APIServer      string        `yaml:"api_server"`
+	CACertFile     string        `yaml:"ca_cert_file"`
+	Namespace      string        `yaml:"namespace"`
+	PodCondition   string        `yaml:"pod_condition"`
+	PodName        string        `yaml:"pod_name"`
+	ResyncInterval time.Duration `yaml:"resync_interval"`
+	Timeout        time.Duration `yaml:"timeout"`
+	TokenFile      string        `yaml:"token_file"`
+
+	// TLS is nil when the api server is not accessed over https.
+	TLS *tls.Config `yaml:"-"`
+}
+
+func (c *Kubernetes) Preprocess() error {
+	if c.PodCondition == "" {
+		return nil
+	}
+
+	if strings.ContainsAny(c.PodCondition, " \t\n") {
+		return fmt.Errorf("invalid kubernetes pod condition type: %s",
+			c.PodCondition,
+		)
+	}
+	if c.ResyncInterval <= 0 {
+		return fmt.Errorf("invalid kubernetes resync interval: %s",
+			c.ResyncInterval,
+		)
+	}
+
+	if c.APIServer == "" {
+		host, port := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")
+		if host == "" || port == "" {
+			return errors.New("kubernetes api server is not specified (and the healthchecker is not running in-cluster)")
+		}
+		c.APIServer = "https://" + net.JoinHostPort(host, port)
+	}
+	apiServer, err := url.Parse(c.APIServer)
+	if err != nil {
+		return fmt.Errorf("invalid kubernetes api server: %w",
+			err,
+		)
+	}
+
+	if c.Namespace == "" {
+		namespace, err := os.ReadFile(KubernetesServiceAccountDir + "/namespace")
+		if err != nil {
+			return fmt.Errorf("failed to detect kubernetes namespace: %w",
+				err,
+			)
+		}
+		c.Namespace = strings.TrimSpace(string(namespace))
+	}
+
+	if c.PodName == "" {
+		c.PodName = os.Getenv("POD_NAME")
+	}
+	if c.PodName == "" {
+		hostname, err := os.Hostname()
+		if err != nil {
+			return fmt.Errorf("failed to detect kubernetes pod name: %w",
+				err,
+			)
+		}
+		c.PodName = hostname
+	}
+
+	if c.TokenFile != "" {
+		if _, err := os.Stat(c.TokenFile); err != nil {
+			return fmt.Errorf("invalid kubernetes token file: %w",
+				err,
+			)
+		}
+	}
+
+	if apiServer.Scheme != "https" {
+		return nil
+	}
+	c.TLS = &tls.Config{
+		MinVersion: tls.VersionTLS12,
+	}
+	if c.CACertFile != "" {
+		pem, err := os.ReadFile(c.CACertFile)
+		if err != nil {
+			return fmt.Errorf("failed to read the kubernetes ca file: %w",
+				err,
+			)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("%w: %s",
+				errKubernetesInvalidCACert,
+				c.CACertFile,
+			)
+		}
+		c.TLS.RootCAs = pool
+	}
+
+	return nil
+}
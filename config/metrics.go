@@ -0,0 +1,44 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+type Metrics struct {
+	Prometheus bool        `yaml:"prometheus"`
+	OTLP       MetricsOTLP `yaml:"otlp"`
+
+	// ResourceAttributes are attached to all exported metrics and traces (for
+	// example `service.instance.id` or the labels of the node).
+	ResourceAttributes StringMap `yaml:"resource_attributes"`
+}
+
+// MetricsOTLP is the configuration of the metrics exporter that pushes them
+// to the OpenTelemetry collector.
+type MetricsOTLP struct {
+	OTLP `yaml:",inline"`
+
+	Interval time.Duration `yaml:"interval"`
+}
+
+func (c *Metrics) Preprocess() error {
+	if !c.Prometheus && c.OTLP.Endpoint == "" {
+		return errors.New("at least one metrics exporter (prometheus or otlp) must be enabled")
+	}
+	if c.OTLP.Endpoint == "" {
+		return nil
+	}
+	if c.OTLP.Interval <= 0 {
+		return fmt.Errorf("invalid otlp metrics export interval: %s",
+			c.OTLP.Interval,
+		)
+	}
+	if err := c.OTLP.Preprocess(); err != nil {
+		return fmt.Errorf("metrics: %w",
+			err,
+		)
+	}
+	return nil
+}
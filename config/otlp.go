@@ -0,0 +1,102 @@
+package config
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+)
+
+const (
+	OTLPProtocolGRPC = "grpc"
+	OTLPProtocolHTTP = "http"
+)
+
+var (
+	errOTLPInvalidCACert = errors.New("no valid certificates found in the ca file")
+)
+
+// OTLP is the configuration of the connection to the OpenTelemetry collector.
+type OTLP struct {
+	Endpoint string    `yaml:"endpoint"`
+	Headers  StringMap `yaml:"headers" secret:"true"`
+	Protocol string    `yaml:"protocol"`
+
+	Insecure    bool   `yaml:"insecure"`
+	TLSCACert   string `yaml:"tls_ca_cert"`
+	TLSCert     string `yaml:"tls_cert"`
+	TLSKey      string `yaml:"tls_key"`
+	TLSInsecure bool   `yaml:"tls_insecure_skip_verify"`
+
+	// TLS is nil when the defaults are good enough.
+	TLS *tls.Config `yaml:"-"`
+}
+
+func (c *OTLP) Preprocess() error {
+	if c.Endpoint == "" {
+		return nil
+	}
+
+	if _, err := url.Parse(c.Endpoint); err != nil {
+		return fmt.Errorf("invalid otlp endpoint: %w",
+			err,
+		)
+	}
+
+	switch c.Protocol {
+	case "":
+		c.Protocol = OTLPProtocolGRPC
+	case OTLPProtocolGRPC, OTLPProtocolHTTP:
+	default:
+		return fmt.Errorf("invalid otlp protocol (expected '%s' or '%s'): %s",
+			OTLPProtocolGRPC, OTLPProtocolHTTP, c.Protocol,
+		)
+	}
+
+	if (c.TLSCert == "") != (c.TLSKey == "") {
+		return errors.New("both tls certificate and key are required for otlp")
+	}
+	if c.Insecure && (c.TLSCACert != "" || c.TLSCert != "" || c.TLSInsecure) {
+		return errors.New("otlp can not be insecure (plaintext) and use tls at the same time")
+	}
+
+	if c.TLSCACert == "" && c.TLSCert == "" && !c.TLSInsecure {
+		return nil
+	}
+
+	c.TLS = &tls.Config{
+		InsecureSkipVerify: c.TLSInsecure,
+		MinVersion:         tls.VersionTLS12,
+	}
+
+	if c.TLSCACert != "" {
+		pem, err := os.ReadFile(c.TLSCACert)
+		if err != nil {
+			return fmt.Errorf("failed to read the otlp ca file: %w",
+				err,
+			)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("%w: %s",
+				errOTLPInvalidCACert,
+				c.TLSCACert,
+			)
+		}
+		c.TLS.RootCAs = pool
+	}
+
+	if c.TLSCert != "" {
+		cert, err := tls.LoadX509KeyPair(c.TLSCert, c.TLSKey)
+		if err != nil {
+			return fmt.Errorf("failed to load the otlp client certificate: %w",
+				err,
+			)
+		}
+		c.TLS.Certificates = []tls.Certificate{cert}
+	}
+
+	return nil
+}
@@ -0,0 +1,123 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/flashbots/node-healthchecker/policy"
+)
+
+const (
+	StatusOk      = "ok"
+	StatusWarning = "warning"
+	StatusError   = "error"
+)
+
+// PolicyRule maps the results of the healthchecks onto the verdict reported at
+// an endpoint.
+//
+// On the command line it is specified as `[<path>] <status>: <expression>`,
+// for example:
+//
+//	error: !ok("lighthouse") || (!ok("geth") && !ok("reth"))
+//	/el warning: metric("geth", "block_age_seconds") > 24
+//
+// The rules of an endpoint are evaluated in order, and the status of the first
+// one with matching expression becomes the verdict ("ok" if none matches).
+type PolicyRule struct {
+	Path       string `yaml:"path"`
+	Status     string `yaml:"status"`
+	Expression string `yaml:"expression"`
+
+	Compiled *policy.Expression `yaml:"-"`
+}
+
+func (c *PolicyRule) Preprocess() error {
+	if c.Path == "" {
+		c.Path = "/"
+	}
+	if !strings.HasPrefix(c.Path, "/") {
+		return fmt.Errorf("invalid path of policy rule '%s': %s",
+			c.Expression, c.Path,
+		)
+	}
+
+	switch c.Status {
+	case StatusOk, StatusWarning, StatusError:
+	default:
+		return fmt.Errorf("invalid status of policy rule '%s': %s",
+			c.Expression, c.Status,
+		)
+	}
+
+	expr, err := policy.Compile(c.Expression)
+	if err != nil {
+		return fmt.Errorf("invalid policy rule '%s': %w",
+			c.Expression, err,
+		)
+	}
+	c.Compiled = expr
+
+	return nil
+}
+
+// PolicyRules implements `flag.Value` so that the rules can be specified by
+// repeating the command-line flag.
+type PolicyRules []*PolicyRule
+
+func (c *PolicyRules) Set(value string) error {
+	rule := &PolicyRule{}
+
+	head, expression, found := strings.Cut(value, ":")
+	if !found {
+		return fmt.Errorf("invalid policy rule (expected '[<path>] <status>: <expression>'): %s",
+			value,
+		)
+	}
+	rule.Expression = strings.TrimSpace(expression)
+
+	fields := strings.Fields(head)
+	switch len(fields) {
+	case 1:
+		rule.Status = fields[0]
+	case 2:
+		rule.Path, rule.Status = fields[0], fields[1]
+	default:
+		return fmt.Errorf("invalid policy rule (expected '[<path>] <status>: <expression>'): %s",
+			value,
+		)
+	}
+
+	*c = append(*c, rule)
+	return nil
+}
+
+func (c *PolicyRules) String() string {
+	if c == nil {
+		return ""
+	}
+	rules := make([]string, 0, len(*c))
+	for _, rule := range *c {
+		rules = append(rules, strings.TrimSpace(rule.Path+" "+rule.Status)+": "+rule.Expression)
+	}
+	return strings.Join(rules, "; ")
+}
+
+func (c PolicyRules) Preprocess(sources []string) error {
+	errs := make([]error, 0, len(c))
+	for _, rule := range c {
+		if err := rule.Preprocess(); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		for _, source := range rule.Compiled.Sources() {
+			if !slices.Contains(sources, source) {
+				errs = append(errs, fmt.Errorf("policy rule '%s' refers to unknown or disabled source: %s",
+					rule.Expression, source,
+				))
+			}
+		}
+	}
+	return flatten(errs)
+}
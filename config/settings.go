@@ -0,0 +1,153 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	redacted = "[redacted]"
+)
+
+// Setting is a single value of the effective configuration.
+type Setting struct {
+	Key   string
+	Value string
+}
+
+var (
+	typeDuration    = reflect.TypeOf(time.Duration(0))
+	typeRawMessage  = reflect.TypeOf(json.RawMessage{})
+	typeStringList  = reflect.TypeOf(StringList{})
+	typeStringSlice = reflect.TypeOf([]string{})
+)
+
+// Settings flattens the configuration into the list of `section.key` settings
+// (named as in yaml), omitting the zero values and the disabled sections, and
+// redacting the secrets (the fields that are tagged with `secret:"true"`, and
+// everything but the scheme and the host of the ones tagged `secret:"url"`).
+func (c *Config) Settings() []Setting {
+	disabled := make([]string, 0)
+	for section, enabled := range map[string]bool{
+		"consul.":                 c.Consul.Address != "",
+		"haproxy_agent.":          c.HAProxyAgent.ListenAddress != "",
+		"healthcheck_geth.":       c.HealthcheckGeth.BaseURL != "",
+		"healthcheck_lighthouse.": c.HealthcheckLighthouse.BaseURL != "",
+		"healthcheck_op_node.":    c.HealthcheckOpNode.BaseURL != "",
+		"healthcheck_reth.":       c.HealthcheckReth.BaseURL != "",
+		"kubernetes.":             c.Kubernetes.PodCondition != "",
+		"metrics.otlp.":           c.Metrics.OTLP.Endpoint != "",
+		"tracing.":                c.Tracing.OTLP.Endpoint != "",
+	} {
+		if !enabled {
+			disabled = append(disabled, section)
+		}
+	}
+
+	settings := make([]Setting, 0)
+	walkSettings("", reflect.ValueOf(c).Elem(), "", func(key string, value string) {
+		for _, section := range disabled {
+			if strings.HasPrefix(key, section) {
+				return
+			}
+		}
+		settings = append(settings, Setting{Key: key, Value: value})
+	})
+	return settings
+}
+
+func walkSettings(key string, v reflect.Value, secret string, emit func(key, value string)) {
+	if v.IsZero() {
+		return
+	}
+	switch secret {
+	case "true":
+		emit(key, redacted)
+		return
+	case "url":
+		emit(key, redactURL(v.String()))
+		return
+	}
+
+	switch v.Type() {
+	case typeDuration:
+		emit(key, v.Interface().(time.Duration).String())
+		return
+	case typeRawMessage:
+		emit(key, string(v.Bytes()))
+		return
+	case typeStringList, typeStringSlice:
+		emit(key, strings.Join(v.Convert(typeStringSlice).Interface().([]string), ","))
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		walkSettings(key, v.Elem(), "", emit)
+
+	case reflect.Struct:
+		for idx := 0; idx < v.NumField(); idx++ {
+			field := v.Type().Field(idx)
+			if !field.IsExported() {
+				continue
+			}
+			name, opts, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+			if name == "-" {
+				continue
+			}
+			if opts == "inline" {
+				walkSettings(key, v.Field(idx), "", emit)
+				continue
+			}
+			if name == "" {
+				name = strings.ToLower(field.Name)
+			}
+			if key != "" {
+				name = key + "." + name
+			}
+			walkSettings(name, v.Field(idx), field.Tag.Get("secret"), emit)
+		}
+
+	case reflect.Slice, reflect.Array:
+		for idx := 0; idx < v.Len(); idx++ {
+			walkSettings(fmt.Sprintf("%s.%d", key, idx), v.Index(idx), "", emit)
+		}
+
+	case reflect.Map:
+		keys := make([]string, 0, v.Len())
+		for _, k := range v.MapKeys() {
+			keys = append(keys, fmt.Sprint(k.Interface()))
+		}
+		slices.Sort(keys)
+		for _, k := range keys {
+			walkSettings(key+"."+k, v.MapIndex(reflect.ValueOf(k).Convert(v.Type().Key())), "", emit)
+		}
+
+	default:
+		emit(key, fmt.Sprint(v.Interface()))
+	}
+}
+
+// redactURL keeps the scheme and the host of the url, and redacts the rest
+// (as the rpc providers tend to put the credentials into the userinfo, the
+// path or the query).
+func redactURL(value string) string {
+	u, err := url.Parse(value)
+	if err != nil || u.Host == "" {
+		return redacted
+	}
+	res := u.Scheme + "://"
+	if u.User != nil {
+		res += redacted + "@"
+	}
+	res += u.Host
+	if strings.Trim(u.Path, "/") != "" || u.RawQuery != "" || u.Fragment != "" {
+		res += "/" + redacted
+	}
+	return res
+}
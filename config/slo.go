@@ -0,0 +1,129 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SLO is the configuration of the service-level objectives, against which the
+// availability of the sources (and of the node overall) is reported.
+type SLO struct {
+	Objectives      SLOObjectives `yaml:"objectives"`
+	BurnRateWindows StringList    `yaml:"burn_rate_windows"`
+
+	// BurnRateDurations are the parsed burn-rate windows.
+	BurnRateDurations []time.Duration `yaml:"-"`
+}
+
+// SLOObjective is the target availability over a sliding window.
+//
+// On the command line it is specified as `<window>=<target>%`, for example:
+//
+//	30d=99.9%
+type SLOObjective struct {
+	Window string  `yaml:"window"`
+	Target float64 `yaml:"target"` // percentage
+
+	Duration time.Duration `yaml:"-"`
+}
+
+func (c *SLO) Preprocess() error {
+	if len(c.BurnRateWindows) == 0 {
+		c.BurnRateWindows = StringList{"5m", "30m", "1h", "6h"}
+	}
+
+	errs := make([]error, 0)
+	for _, objective := range c.Objectives {
+		errs = append(errs, objective.Preprocess())
+	}
+
+	c.BurnRateDurations = make([]time.Duration, 0, len(c.BurnRateWindows))
+	for _, window := range c.BurnRateWindows {
+		duration, err := ParseWindow(window)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("invalid slo burn-rate window: %w",
+				err,
+			))
+			continue
+		}
+		c.BurnRateDurations = append(c.BurnRateDurations, duration)
+	}
+
+	return flatten(errs)
+}
+
+func (c *SLOObjective) Preprocess() error {
+	duration, err := ParseWindow(c.Window)
+	if err != nil {
+		return fmt.Errorf("invalid slo window: %w",
+			err,
+		)
+	}
+	c.Duration = duration
+
+	if c.Target <= 0 || c.Target >= 100 {
+		return fmt.Errorf("invalid slo target (expected a percentage within (0%%, 100%%)): %v%%",
+			c.Target,
+		)
+	}
+	return nil
+}
+
+// SLOObjectives implements `flag.Value` so that the objectives can be
+// specified by repeating the command-line flag.
+type SLOObjectives []*SLOObjective
+
+func (c *SLOObjectives) Set(value string) error {
+	window, target, found := strings.Cut(value, "=")
+	if !found {
+		return fmt.Errorf("invalid slo objective (expected '<window>=<target>%%'): %s",
+			value,
+		)
+	}
+	pct, err := strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(target), "%"), 64)
+	if err != nil {
+		return fmt.Errorf("invalid slo objective (expected '<window>=<target>%%'): %s",
+			value,
+		)
+	}
+	*c = append(*c, &SLOObjective{
+		Window: strings.TrimSpace(window),
+		Target: pct,
+	})
+	return nil
+}
+
+func (c *SLOObjectives) String() string {
+	if c == nil {
+		return ""
+	}
+	objectives := make([]string, 0, len(*c))
+	for _, objective := range *c {
+		objectives = append(objectives, objective.Window+"="+strconv.FormatFloat(objective.Target, 'f', -1, 64)+"%")
+	}
+	return strings.Join(objectives, ", ")
+}
+
+// ParseWindow parses the duration of a sliding window, which (unlike
+// `time.ParseDuration`) also accepts days, e.g. `30d`.
+func ParseWindow(window string) (time.Duration, error) {
+	var (
+		duration time.Duration
+		err      error
+	)
+	if days, found := strings.CutSuffix(window, "d"); found {
+		var num uint64
+		num, err = strconv.ParseUint(days, 10, 64)
+		duration = time.Duration(num) * 24 * time.Hour
+	} else {
+		duration, err = time.ParseDuration(window)
+	}
+	if err != nil || duration <= 0 {
+		return 0, fmt.Errorf("expected positive duration (e.g. '5m', '6h' or '30d'): %s",
+			window,
+		)
+	}
+	return duration, nil
+}
@@ -0,0 +1,23 @@
+package config
+
+import "strings"
+
+// StringList implements `flag.Value` for the comma-separated lists (that can
+// also be specified by repeating the command-line flag).
+type StringList []string
+
+func (c *StringList) Set(value string) error {
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			*c = append(*c, item)
+		}
+	}
+	return nil
+}
+
+func (c *StringList) String() string {
+	if c == nil {
+		return ""
+	}
+	return strings.Join(*c, ",")
+}
@@ -0,0 +1,37 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// StringMap implements `flag.Value` for the `key=value` pairs (specified by
+// repeating the command-line flag).
+type StringMap map[string]string
+
+func (c *StringMap) Set(value string) error {
+	key, val, found := strings.Cut(value, "=")
+	if key = strings.TrimSpace(key); !found || key == "" {
+		return fmt.Errorf("invalid key-value pair (expected 'key=value'): %s",
+			value,
+		)
+	}
+	if *c == nil {
+		*c = make(StringMap)
+	}
+	(*c)[key] = strings.TrimSpace(val)
+	return nil
+}
+
+func (c *StringMap) String() string {
+	if c == nil {
+		return ""
+	}
+	pairs := make([]string, 0, len(*c))
+	for key, val := range *c {
+		pairs = append(pairs, key+"="+val)
+	}
+	slices.Sort(pairs)
+	return strings.Join(pairs, ",")
+}
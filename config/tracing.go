@@ -0,0 +1,27 @@
+package config
+
+import (
+	"fmt"
+)
+
+type Tracing struct {
+	OTLP        OTLP    `yaml:"otlp"`
+	SampleRatio float64 `yaml:"sample_ratio"`
+}
+
+func (c *Tracing) Preprocess() error {
+	if c.OTLP.Endpoint == "" {
+		return nil
+	}
+	if c.SampleRatio < 0 || c.SampleRatio > 1 {
+		return fmt.Errorf("invalid tracing sample ratio (must be within [0, 1]): %f",
+			c.SampleRatio,
+		)
+	}
+	if err := c.OTLP.Preprocess(); err != nil {
+		return fmt.Errorf("tracing: %w",
+			err,
+		)
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package config
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+func validateHex(str string, length int) error {
+	if !strings.HasPrefix(str, "0x") {
+		return fmt.Errorf("missing 0x prefix: %s",
+			str,
+		)
+	}
+	bytes, err := hex.DecodeString(strings.TrimPrefix(str, "0x"))
+	if err != nil {
+		return err
+	}
+	if len(bytes) != length {
+		return fmt.Errorf("unexpected length (expected %d bytes, got %d): %s",
+			length, len(bytes), str,
+		)
+	}
+	return nil
+}
+
+func validateHexQuantity(str string, maxLength int) error {
+	if !strings.HasPrefix(str, "0x") {
+		return fmt.Errorf("missing 0x prefix: %s",
+			str,
+		)
+	}
+	digits := strings.TrimPrefix(str, "0x")
+	if len(digits)%2 == 1 {
+		digits = "0" + digits
+	}
+	bytes, err := hex.DecodeString(digits)
+	if err != nil {
+		return err
+	}
+	if len(bytes) == 0 || len(bytes) > maxLength {
+		return fmt.Errorf("unexpected length (expected up to %d bytes, got %d): %s",
+			maxLength, len(bytes), str,
+		)
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+type Webhook struct {
+	Endpoints   WebhookEndpoints `yaml:"endpoints"`
+	DedupWindow time.Duration    `yaml:"dedup_window"`
+	Retries     int              `yaml:"retries"`
+	RetryDelay  time.Duration    `yaml:"retry_delay"`
+	Timeout     time.Duration    `yaml:"timeout"`
+}
+
+func (c *Webhook) Preprocess() error {
+	if c.Retries < 0 {
+		return fmt.Errorf("invalid webhook retries: %d",
+			c.Retries,
+		)
+	}
+	return c.Endpoints.Preprocess()
+}
+
+// WebhookEndpoint is the destination of the webhook notifications.
+//
+// On the command line it is specified either as a plain URL, or as JSON, for
+// example:
+//
+//	{"url":"https://hooks.slack.com/services/XXX","min_severity":"error","template":"{\"text\":{{ printf \"%s is %s: %s\" .Source .To .Message | json }}}"}
+type WebhookEndpoint struct {
+	URL         string            `yaml:"url"          json:"url"          secret:"true"`
+	Headers     map[string]string `yaml:"headers"      json:"headers"      secret:"true"`
+	MinSeverity string            `yaml:"min_severity" json:"min_severity"`
+	Template    string            `yaml:"template"     json:"template"`
+
+	err error // of parsing the command-line flag (reported on preprocess)
+}
+
+func (c *WebhookEndpoint) Preprocess() error {
+	if c.err != nil {
+		return c.err
+	}
+	if c.URL == "" {
+		return errors.New("webhook url is required")
+	}
+	if _, err := url.Parse(c.URL); err != nil {
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err // without the url itself
+		}
+		return fmt.Errorf("invalid webhook url: %w",
+			err,
+		)
+	}
+
+	switch strings.ToLower(c.MinSeverity) {
+	case "", SeverityWarning:
+		c.MinSeverity = SeverityWarning
+	case SeverityError:
+		c.MinSeverity = SeverityError
+	default:
+		return fmt.Errorf("invalid minimum severity of webhook '%s': %s",
+			c.Host(), c.MinSeverity,
+		)
+	}
+
+	return nil
+}
+
+// Host returns the host of the webhook url, which (unlike the url itself) is
+// safe to log.
+func (c *WebhookEndpoint) Host() string {
+	u, err := url.Parse(c.URL)
+	if err != nil {
+		return ""
+	}
+	return u.Host
+}
+
+// WebhookEndpoints implements `flag.Value` so that webhooks can be specified by
+// repeating the command-line flag.
+type WebhookEndpoints []*WebhookEndpoint
+
+// Set never fails, as the flag package would echo the (possibly secret) value
+// in the error. The parsing error is reported by preprocess instead.
+func (c *WebhookEndpoints) Set(value string) error {
+	endpoint := &WebhookEndpoint{}
+	if strings.HasPrefix(strings.TrimSpace(value), "{") {
+		if err := json.Unmarshal([]byte(value), endpoint); err != nil {
+			endpoint = &WebhookEndpoint{
+				err: fmt.Errorf("invalid webhook json: %w",
+					err,
+				),
+			}
+		}
+	} else {
+		endpoint.URL = value
+	}
+	*c = append(*c, endpoint)
+	return nil
+}
+
+// String returns the hosts of the webhooks only (their urls might carry the
+// secrets).
+func (c *WebhookEndpoints) String() string {
+	if c == nil {
+		return ""
+	}
+	hosts := make([]string, 0, len(*c))
+	for _, endpoint := range *c {
+		hosts = append(hosts, endpoint.Host())
+	}
+	return strings.Join(hosts, ", ")
+}
+
+func (c WebhookEndpoints) Preprocess() error {
+	errs := make([]error, 0, len(c))
+	for _, endpoint := range c {
+		errs = append(errs, endpoint.Preprocess())
+	}
+	return flatten(errs)
+}
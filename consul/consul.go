@@ -0,0 +1,185 @@
+// Package consul registers the node's service in the local consul agent, and
+// keeps its ttl check updated with the verdict of the healthchecker.
+//
+// See: https://developer.hashicorp.com/consul/api-docs/agent/service
+package consul
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/flashbots/node-healthchecker/config"
+	"github.com/flashbots/node-healthchecker/healthcheck"
+)
+
+const (
+	checkPassing  = "passing"
+	checkWarning  = "warning"
+	checkCritical = "critical"
+
+	maxOutputLength = 4096
+)
+
+// Agent talks to the local consul agent on behalf of the node's service.
+type Agent struct {
+	cfg *config.Consul
+
+	client  *http.Client
+	checkID string
+}
+
+type service struct {
+	ID      string   `json:"ID"`
+	Name    string   `json:"Name"`
+	Tags    []string `json:"Tags,omitempty"`
+	Address string   `json:"Address,omitempty"`
+	Port    int      `json:"Port,omitempty"`
+	Check   *check   `json:"Check"`
+}
+
+type check struct {
+	CheckID                        string `json:"CheckID"`
+	Name                           string `json:"Name"`
+	TTL                            string `json:"TTL"`
+	Status                         string `json:"Status"`
+	DeregisterCriticalServiceAfter string `json:"DeregisterCriticalServiceAfter,omitempty"`
+}
+
+type checkUpdate struct {
+	Status string `json:"Status"`
+	Output string `json:"Output"`
+}
+
+func New(cfg *config.Consul) *Agent {
+	return &Agent{
+		cfg:     cfg,
+		client:  &http.Client{Timeout: cfg.Timeout},
+		checkID: "service:" + cfg.ServiceID,
+	}
+}
+
+// Register registers the service along with its ttl check (which starts in
+// critical state until the first update).
+func (a *Agent) Register(ctx context.Context) error {
+	svc := &service{
+		ID:      a.cfg.ServiceID,
+		Name:    a.cfg.ServiceName,
+		Tags:    a.cfg.ServiceTags,
+		Address: a.cfg.ServiceAddress,
+		Port:    a.cfg.ServicePort,
+		Check: &check{
+			CheckID: a.checkID,
+			Name:    "node-healthchecker",
+			TTL:     a.cfg.CheckTTL.String(),
+			Status:  checkCritical,
+		},
+	}
+	if a.cfg.DeregisterCriticalServiceAfter > 0 {
+		svc.Check.DeregisterCriticalServiceAfter = a.cfg.DeregisterCriticalServiceAfter.String()
+	}
+
+	if err := a.put(ctx, "/v1/agent/service/register", svc); err != nil {
+		return fmt.Errorf("failed to register consul service '%s': %w",
+			a.cfg.ServiceID, err,
+		)
+	}
+	return nil
+}
+
+// Update reports the status (along with the details) to the ttl check of the
+// service.
+// truncate cuts the text down to at most limit bytes (without splitting the
+// last rune).
+func truncate(text string, limit int) string {
+	if len(text) <= limit {
+		return text
+	}
+	end := limit
+	for end > 0 && !utf8.RuneStart(text[end]) {
+		end--
+	}
+	return text[:end]
+}
+
+func (a *Agent) Update(ctx context.Context, status healthcheck.Status, output string) error {
+	update := &checkUpdate{
+		Status: checkStatus(status),
+		Output: truncate(output, maxOutputLength),
+	}
+
+	if err := a.put(ctx, "/v1/agent/check/update/"+url.PathEscape(a.checkID), update); err != nil {
+		return fmt.Errorf("failed to update consul check '%s': %w",
+			a.checkID, err,
+		)
+	}
+	return nil
+}
+
+// Deregister removes the service (and its check) from the agent.
+func (a *Agent) Deregister(ctx context.Context) error {
+	if err := a.put(ctx, "/v1/agent/service/deregister/"+url.PathEscape(a.cfg.ServiceID), nil); err != nil {
+		return fmt.Errorf("failed to deregister consul service '%s': %w",
+			a.cfg.ServiceID, err,
+		)
+	}
+	return nil
+}
+
+func (a *Agent) put(ctx context.Context, path string, payload any) error {
+	var body io.Reader
+	if payload != nil {
+		b, err := json.Marshal(payload)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(b)
+	}
+
+	req, err := http.NewRequestWithContext(
+		ctx,
+		http.MethodPut,
+		strings.TrimSuffix(a.cfg.Address, "/")+path,
+		body,
+	)
+	if err != nil {
+		return err
+	}
+	if payload != nil {
+		req.Header.Set("content-type", "application/json")
+	}
+	if a.cfg.Token != "" {
+		req.Header.Set("x-consul-token", a.cfg.Token)
+	}
+
+	res, err := a.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+		return fmt.Errorf("unexpected http status %d: %s",
+			res.StatusCode, strings.TrimSpace(string(msg)),
+		)
+	}
+	return nil
+}
+
+func checkStatus(status healthcheck.Status) string {
+	switch status {
+	case healthcheck.StatusOk:
+		return checkPassing
+	case healthcheck.StatusWarning:
+		return checkWarning
+	default:
+		return checkCritical
+	}
+}
@@ -0,0 +1,36 @@
+package consul
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		text     string
+		limit    int
+		expected string
+	}{
+		{"geth: ok", 16, "geth: ok"},
+		{"geth: ok", 8, "geth: ok"},
+		{"geth: ok", 4, "geth"},
+		{"блок", 8, "блок"},
+		{"блок", 7, "бло"}, // the last rune would be split
+		{"блок", 6, "бло"},
+		{"⏳ syncing", 2, ""},
+		{"⏳ syncing", 3, "⏳"},
+	}
+
+	for _, tt := range tests {
+		actual := truncate(tt.text, tt.limit)
+		if actual != tt.expected {
+			t.Errorf("truncate(%q, %d): expected %q, got %q", tt.text, tt.limit, tt.expected, actual)
+		}
+	}
+
+	long := strings.Repeat("я", maxOutputLength) // 2 bytes each
+	if actual := truncate("x"+long, maxOutputLength); len(actual) != maxOutputLength-1 || !utf8.ValidString(actual) {
+		t.Errorf("expected valid utf-8 of %d bytes, got %d bytes (valid: %t)", maxOutputLength-1, len(actual), utf8.ValidString(actual))
+	}
+}
@@ -0,0 +1,135 @@
+package healthcheck
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strings"
+
+	"github.com/flashbots/node-healthchecker/config"
+)
+
+var (
+	errArchiveStatePruned = errors.New("historical state is not available")
+)
+
+// archivePrunedMessages are the (lower-cased) fragments of the error messages
+// that execution clients return when the requested state was pruned.
+var archivePrunedMessages = []string{
+	"missing trie node",
+	"state pruned",
+	"historical state",
+	"state not available",
+	"state is not available",
+}
+
+// archiveResponse is the response to `eth_getBalance` or `eth_getStorageAt`.
+type archiveResponse struct {
+	Result *string `json:"result"`
+	Error  *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// archive verifies that the historical state at the configured block (or at a
+// random block from the configured range) is still queryable.
+func archive(ctx context.Context, client *client, baseURL string, cfg *config.HealthcheckArchive) error {
+	block := cfg.BlockFrom
+	if cfg.BlockTo > cfg.BlockFrom {
+		block += rand.Uint64N(cfg.BlockTo - cfg.BlockFrom + 1)
+	}
+	blockHex := fmt.Sprintf("0x%x", block)
+
+	// https://ethereum.org/en/developers/docs/apis/json-rpc/#eth_getbalance
+	// https://ethereum.org/en/developers/docs/apis/json-rpc/#eth_getstorageat
+
+	method := "eth_getBalance"
+	params := []string{cfg.Address, blockHex}
+	if cfg.StorageSlot != "" {
+		method = "eth_getStorageAt"
+		params = []string{cfg.Address, cfg.StorageSlot, blockHex}
+	}
+
+	payload, err := json.Marshal(map[string]any{
+		"jsonrpc": "2.0",
+		"method":  method,
+		"params":  params,
+		"id":      0,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(
+		ctx,
+		http.MethodPost,
+		baseURL,
+		bytes.NewReader(payload),
+	)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("accept", "application/json")
+	req.Header.Set("content-type", "application/json")
+
+	res, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected HTTP status '%d': %s",
+			res.StatusCode,
+			string(body),
+		)
+	}
+
+	var response archiveResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return fmt.Errorf("failed to parse JSON body '%s': %w",
+			string(body),
+			err,
+		)
+	}
+
+	if response.Error != nil {
+		msg := strings.ToLower(response.Error.Message)
+		for _, pruned := range archivePrunedMessages {
+			if strings.Contains(msg, pruned) {
+				return fmt.Errorf("%w: %s at block %d failed: %s",
+					errArchiveStatePruned,
+					method,
+					block,
+					response.Error.Message,
+				)
+			}
+		}
+		return fmt.Errorf("%s at block %d failed (code: %d): %s",
+			method,
+			block,
+			response.Error.Code,
+			response.Error.Message,
+		)
+	}
+
+	if response.Result == nil {
+		return fmt.Errorf("%s at block %d returned empty result",
+			method,
+			block,
+		)
+	}
+
+	return nil
+}
@@ -0,0 +1,180 @@
+package healthcheck
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/flashbots/node-healthchecker/config"
+)
+
+// engineResponse is the generic response of the engine api.
+type engineResponse struct {
+	Result json.RawMessage `json:"result"`
+	Error  *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// engineCapabilities is the list of methods we announce to the execution
+// client in the `engine_exchangeCapabilities` call.
+//
+// See: https://github.com/ethereum/execution-apis/blob/main/src/engine/common.md#engine_exchangecapabilities
+var engineCapabilities = []string{
+	"engine_exchangeCapabilities",
+	"engine_getClientVersionV1",
+}
+
+// engineClientVersion is how we identify ourselves in `engine_getClientVersionV1`.
+//
+// See: https://github.com/ethereum/execution-apis/blob/main/src/engine/identification.md
+var engineClientVersion = map[string]string{
+	"code":    "NH",
+	"name":    "node-healthchecker",
+	"version": "",
+	"commit":  "0x00000000",
+}
+
+// engine checks the connectivity with the engine api (aka authrpc) of the
+// execution client.
+func engine(ctx context.Context, client *client, cfg *config.HealthcheckEngine) error {
+	{ // engine_exchangeCapabilities
+		if _, err := engineCall(ctx, client, cfg, "engine_exchangeCapabilities", engineCapabilities); err != nil {
+			return err
+		}
+	}
+
+	{ // engine_getClientVersionV1
+		if cfg.ClientVersion {
+			if _, err := engineCall(ctx, client, cfg, "engine_getClientVersionV1", engineClientVersion); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func engineCall(ctx context.Context, client *client, cfg *config.HealthcheckEngine, method string, param any) (json.RawMessage, error) {
+	payload, err := json.Marshal(map[string]any{
+		"jsonrpc": "2.0",
+		"method":  method,
+		"params":  []any{param},
+		"id":      0,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := engineToken(cfg.JWTSecret, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(
+		ctx,
+		http.MethodPost,
+		cfg.BaseURL,
+		bytes.NewReader(payload),
+	)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("accept", "application/json")
+	req.Header.Set("authorization", "Bearer "+token)
+	req.Header.Set("content-type", "application/json")
+
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode == http.StatusUnauthorized || res.StatusCode == http.StatusForbidden {
+		return nil, fmt.Errorf("jwt token rejected by %s (HTTP status '%d'): %s",
+			method,
+			res.StatusCode,
+			string(body),
+		)
+	}
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected HTTP status '%d' from %s: %s",
+			res.StatusCode,
+			method,
+			string(body),
+		)
+	}
+
+	var response engineResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON body '%s': %w",
+			string(body),
+			err,
+		)
+	}
+
+	if response.Error != nil {
+		return nil, fmt.Errorf("%s failed (code: %d): %s",
+			method,
+			response.Error.Code,
+			response.Error.Message,
+		)
+	}
+
+	if len(response.Result) == 0 || string(response.Result) == "null" {
+		return nil, fmt.Errorf("%s returned empty result",
+			method,
+		)
+	}
+
+	return response.Result, nil
+}
+
+// engineToken returns HS256-signed JWT token as per the engine api spec.
+//
+// See: https://github.com/ethereum/execution-apis/blob/main/src/engine/authentication.md
+func engineToken(secret []byte, now time.Time) (string, error) {
+	if len(secret) == 0 {
+		return "", errors.New("jwt secret is not configured")
+	}
+
+	header, err := json.Marshal(map[string]string{
+		"alg": "HS256",
+		"typ": "JWT",
+	})
+	if err != nil {
+		return "", err
+	}
+
+	claims, err := json.Marshal(map[string]int64{
+		"iat": now.Unix(),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	unsigned := base64.RawURLEncoding.EncodeToString(header) +
+		"." +
+		base64.RawURLEncoding.EncodeToString(claims)
+
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(unsigned))
+	signature := base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+
+	return unsigned + "." + signature, nil
+}
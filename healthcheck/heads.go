@@ -0,0 +1,224 @@
+package healthcheck
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
+
+	"github.com/flashbots/node-healthchecker/logutils"
+)
+
+const (
+	headsBackoffMin   = time.Second
+	headsBackoffMax   = 30 * time.Second
+	headsPingPeriod   = 15 * time.Second
+	headsReadTimeout  = 2 * headsPingPeriod
+	headsWriteTimeout = 5 * time.Second
+)
+
+var (
+	errHeadsNotConnected = errors.New("newHeads subscription is not established")
+	errHeadsNoHeads      = errors.New("newHeads subscription has not received any heads yet")
+)
+
+// headsMessage is either the response to `eth_subscribe` or the subscription
+// notification.
+type headsMessage struct {
+	ID     *int            `json:"id"`
+	Result json.RawMessage `json:"result"`
+	Error  *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+
+	Method string `json:"method"`
+	Params struct {
+		Subscription string `json:"subscription"`
+		Result       struct {
+			Number    string `json:"number"`
+			Timestamp string `json:"timestamp"`
+		} `json:"result"`
+	} `json:"params"`
+}
+
+// Heads keeps track of the latest head of an execution client by means of the
+// persistent `eth_subscribe("newHeads")` websocket subscription.
+type Heads struct {
+	url string
+
+	connected bool
+	err       error
+	number    string
+	timestamp time.Time
+
+	mx sync.RWMutex
+}
+
+// Head is the latest known head.
+type Head struct {
+	Number    string
+	Timestamp time.Time
+}
+
+func NewHeads(url string) *Heads {
+	return &Heads{
+		url: url,
+		err: errHeadsNotConnected,
+	}
+}
+
+// Latest returns the latest head, or an error if the subscription is down.
+func (h *Heads) Latest() (*Head, error) {
+	h.mx.RLock()
+	defer h.mx.RUnlock()
+
+	if !h.connected {
+		return nil, h.err
+	}
+	if h.timestamp.IsZero() {
+		return nil, errHeadsNoHeads
+	}
+	return &Head{
+		Number:    h.number,
+		Timestamp: h.timestamp,
+	}, nil
+}
+
+// Run maintains the subscription (re-connecting with exponential backoff)
+// until the context is cancelled.
+func (h *Heads) Run(ctx context.Context) {
+	l := logutils.LoggerFromContext(ctx).With(
+		zap.String("websocket_url", h.url),
+	)
+
+	backoff := headsBackoffMin
+	for {
+		subscribed, err := h.subscribe(ctx)
+
+		h.mx.Lock()
+		h.connected = false
+		h.err = fmt.Errorf("newHeads subscription is down: %w", err)
+		h.mx.Unlock()
+
+		if ctx.Err() != nil {
+			return
+		}
+
+		if subscribed {
+			backoff = headsBackoffMin
+		}
+		l.Warn("NewHeads subscription dropped; re-connecting...",
+			zap.Error(err),
+			zap.Duration("backoff", backoff),
+		)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+
+		backoff = min(2*backoff, headsBackoffMax)
+	}
+}
+
+func (h *Heads) subscribe(ctx context.Context) (subscribed bool, err error) {
+	conn, _, err := websocket.DefaultDialer.DialContext(ctx, h.url, nil)
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close()
+
+	// the node answers our pings with pongs, so the reads time out only on
+	// the half-open connections
+	conn.SetReadDeadline(time.Now().Add(headsReadTimeout)) //nolint:errcheck
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(headsReadTimeout))
+	})
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() { // close the connection on cancel and keep it alive meanwhile
+		ticker := time.NewTicker(headsPingPeriod)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				conn.Close()
+				return
+			case <-done:
+				return
+			case <-ticker.C:
+				deadline := time.Now().Add(headsWriteTimeout)
+				if err := conn.WriteControl(websocket.PingMessage, nil, deadline); err != nil {
+					conn.Close()
+					return
+				}
+			}
+		}
+	}()
+
+	// https://geth.ethereum.org/docs/interacting-with-geth/rpc/pubsub#newheads
+
+	const ethSubscribe = `{"jsonrpc":"2.0","method":"eth_subscribe","params":["newHeads"],"id":1}`
+
+	conn.SetWriteDeadline(time.Now().Add(headsWriteTimeout)) //nolint:errcheck
+	if err := conn.WriteMessage(websocket.TextMessage, []byte(ethSubscribe)); err != nil {
+		return false, err
+	}
+
+	for {
+		_, body, err := conn.ReadMessage()
+		if err != nil {
+			return subscribed, err
+		}
+		conn.SetReadDeadline(time.Now().Add(headsReadTimeout)) //nolint:errcheck
+
+		var msg headsMessage
+		if err := json.Unmarshal(body, &msg); err != nil {
+			return subscribed, fmt.Errorf("failed to parse JSON message '%s': %w",
+				string(body),
+				err,
+			)
+		}
+
+		switch {
+		case msg.ID != nil && msg.Error != nil:
+			return subscribed, fmt.Errorf("eth_subscribe failed (code: %d): %s",
+				msg.Error.Code,
+				msg.Error.Message,
+			)
+
+		case msg.ID != nil:
+			subscribed = true
+			h.mx.Lock()
+			h.connected = true
+			h.err = nil
+			h.mx.Unlock()
+
+		case msg.Method == "eth_subscription":
+			epoch, err := strconv.ParseInt(
+				strings.TrimPrefix(msg.Params.Result.Timestamp, "0x"),
+				16, 64,
+			)
+			if err != nil {
+				return subscribed, fmt.Errorf("failed to parse hex timestamp '%s': %w",
+					msg.Params.Result.Timestamp,
+					err,
+				)
+			}
+			h.mx.Lock()
+			h.number = msg.Params.Result.Number
+			h.timestamp = time.Unix(epoch, 0)
+			h.mx.Unlock()
+		}
+	}
+}
@@ -3,6 +3,9 @@ package healthcheck
 import (
 	"context"
 	"fmt"
+	"strconv"
+	"strings"
+	"time"
 )
 
 type Monitor = func(context.Context) *Result
@@ -11,6 +14,20 @@ type Result struct {
 	Source string
 	Ok     bool
 	Err    error
+
+	// Skipped means that the healthcheck was not run at all (as some of its
+	// dependencies are unhealthy), and Err tells which one.
+	Skipped bool
+
+	// Retries is the number of times the requests of the healthcheck were
+	// retried (as per the retry policy of the source).
+	Retries int
+
+	// Latency is how long the healthcheck took.
+	Latency time.Duration
+
+	// Metrics are the numeric observations collected during the healthcheck.
+	Metrics map[string]float64
 }
 
 func (r *Result) Error() error {
@@ -20,9 +37,52 @@ func (r *Result) Error() error {
 	)
 }
 
+func (r *Result) setMetric(name string, value float64) {
+	if r.Metrics == nil {
+		r.Metrics = make(map[string]float64)
+	}
+	r.Metrics[name] = value
+}
+
+// setHexMetric sets the metric from hex-encoded quantity (if it's valid).
+func (r *Result) setHexMetric(name string, value string) {
+	if v, err := strconv.ParseUint(strings.TrimPrefix(value, "0x"), 16, 64); err == nil {
+		r.setMetric(name, float64(v))
+	}
+}
+
+// setSlotDistanceMetric sets the metric to the distance between the slots (if
+// they are valid).
+func (r *Result) setSlotDistanceMetric(name, from, to string) {
+	_from, err := strconv.ParseUint(from, 10, 64)
+	if err != nil {
+		return
+	}
+	_to, err := strconv.ParseUint(to, 10, 64)
+	if err != nil || _to < _from {
+		return
+	}
+	r.setMetric(name, float64(_to-_from))
+}
+
 const (
 	SourceGeth       = "geth"
 	SourceLighthouse = "lighthouse"
 	SourceOpNode     = "op-node"
 	SourceReth       = "reth"
 )
+
+const (
+	MetricBlockAge     = "block_age_seconds"
+	MetricCurrentL1    = "current_l1"
+	MetricFinalizedL2  = "finalized_l2"
+	MetricHeadL1       = "head_l1"
+	MetricHeadSlot     = "head_slot"
+	MetricL1Distance   = "l1_distance"
+	MetricLatestBlock  = "latest_block"
+	MetricSafeL2       = "safe_l2"
+	MetricSyncDistance = "sync_distance"
+	MetricSyncCurrent  = "sync_current_block"
+	MetricSyncHighest  = "sync_highest_block"
+	MetricUnsafeL2     = "unsafe_l2"
+)
@@ -9,6 +9,7 @@ import (
 	"net/http"
 	"net/url"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/flashbots/node-healthchecker/config"
@@ -68,6 +69,15 @@ type lighthouseStateAsStruct struct {
 	} `json:"data"`
 }
 
+// lighthouseBeaconGenesis represents the genesis details of the beacon chain.
+type lighthouseBeaconGenesis struct {
+	Data struct {
+		GenesisTime           string `json:"genesis_time"`
+		GenesisValidatorsRoot string `json:"genesis_validators_root"`
+		GenesisForkVersion    string `json:"genesis_fork_version"`
+	} `json:"data"`
+}
+
 type lighthouseBeaconBlocksHead struct {
 	Data struct {
 		Message struct {
@@ -84,6 +94,77 @@ type lighthouseBeaconBlocksHead struct {
 
 func Lighthouse(ctx context.Context, cfg *config.HealthcheckLighthouse) (healthcheck *Result) {
 	healthcheck = &Result{Source: SourceLighthouse}
+	client := newClient(&cfg.Retry, healthcheck)
+
+	{ // eth/v1/beacon/genesis
+		if cfg.GenesisValidatorsRoot != "" || cfg.GenesisForkVersion != "" {
+			// https://ethereum.github.io/beacon-APIs/#/Beacon/getGenesis
+
+			_url, err := url.JoinPath(cfg.BaseURL, "eth/v1/beacon/genesis")
+			if err != nil {
+				healthcheck.Err = err
+				return
+			}
+
+			req, err := http.NewRequestWithContext(
+				ctx,
+				http.MethodGet,
+				_url,
+				nil,
+			)
+			if err != nil {
+				healthcheck.Err = err
+				return
+			}
+			req.Header.Set("accept", "application/json")
+
+			res, err := client.Do(req)
+			if err != nil {
+				healthcheck.Err = err
+				return
+			}
+			defer res.Body.Close()
+
+			body, err := io.ReadAll(res.Body)
+			if err != nil {
+				healthcheck.Err = err
+				return
+			}
+
+			if res.StatusCode != http.StatusOK {
+				healthcheck.Err = fmt.Errorf("unexpected HTTP status '%d': %s",
+					res.StatusCode,
+					string(body),
+				)
+				return
+			}
+
+			var genesis lighthouseBeaconGenesis
+			if err := json.Unmarshal(body, &genesis); err != nil {
+				healthcheck.Err = fmt.Errorf("failed to parse JSON body '%s': %w",
+					string(body),
+					err,
+				)
+				return
+			}
+
+			if cfg.GenesisValidatorsRoot != "" && !strings.EqualFold(genesis.Data.GenesisValidatorsRoot, cfg.GenesisValidatorsRoot) {
+				healthcheck.Err = fmt.Errorf("unexpected genesis validators root: '%s' != '%s'",
+					genesis.Data.GenesisValidatorsRoot,
+					cfg.GenesisValidatorsRoot,
+				)
+				return
+			}
+
+			if cfg.GenesisForkVersion != "" && !strings.EqualFold(genesis.Data.GenesisForkVersion, cfg.GenesisForkVersion) {
+				healthcheck.Err = fmt.Errorf("unexpected genesis fork version: '%s' != '%s'",
+					genesis.Data.GenesisForkVersion,
+					cfg.GenesisForkVersion,
+				)
+				return
+			}
+		}
+	}
 
 	{ // lighthouse/syncing
 
@@ -108,7 +189,7 @@ func Lighthouse(ctx context.Context, cfg *config.HealthcheckLighthouse) (healthc
 		}
 		req.Header.Set("accept", "application/json")
 
-		res, err := http.DefaultClient.Do(req)
+		res, err := client.Do(req)
 		if err != nil {
 			healthcheck.Err = err
 			return
@@ -147,6 +228,7 @@ func Lighthouse(ctx context.Context, cfg *config.HealthcheckLighthouse) (healthc
 				//
 				// See: https://lighthouse-book.sigmaprime.io/checkpoint-sync.html#backfilling-blocks
 				//
+				healthcheck.setMetric(MetricSyncDistance, 0)
 				healthcheck.Ok = true
 				healthcheck.Err = fmt.Errorf("is in 'BackFillSyncing' state (completed: %d, remaining: %d)",
 					state.Data.BackFillSyncing.Completed,
@@ -154,12 +236,20 @@ func Lighthouse(ctx context.Context, cfg *config.HealthcheckLighthouse) (healthc
 				)
 				return
 			case state.Data.SyncingFinalized != nil:
+				healthcheck.setSlotDistanceMetric(MetricSyncDistance,
+					state.Data.SyncingFinalized.StartSlot,
+					state.Data.SyncingFinalized.TargetSlot,
+				)
 				healthcheck.Err = fmt.Errorf("is in 'SyncingFinalized' state (start_slot: '%s', target_slot: '%s')",
 					state.Data.SyncingFinalized.StartSlot,
 					state.Data.SyncingFinalized.TargetSlot,
 				)
 				return
 			case state.Data.SyncingHead != nil:
+				healthcheck.setSlotDistanceMetric(MetricSyncDistance,
+					state.Data.SyncingHead.StartSlot,
+					state.Data.SyncingHead.TargetSlot,
+				)
 				healthcheck.Err = fmt.Errorf("is in 'SyncingHead' state (start_slot: '%s', target_slot: '%s')",
 					state.Data.SyncingHead.StartSlot,
 					state.Data.SyncingHead.TargetSlot,
@@ -172,6 +262,9 @@ func Lighthouse(ctx context.Context, cfg *config.HealthcheckLighthouse) (healthc
 				return
 			}
 		}
+		if state.Data == "Synced" {
+			healthcheck.setMetric(MetricSyncDistance, 0)
+		}
 		if state.Data != "Synced" {
 			healthcheck.Err = fmt.Errorf("is not in synced state: %s",
 				state.Data,
@@ -203,7 +296,7 @@ func Lighthouse(ctx context.Context, cfg *config.HealthcheckLighthouse) (healthc
 			req.Header.Set("accept", "application/json")
 
 			now := time.Now()
-			res, err := http.DefaultClient.Do(req)
+			res, err := client.Do(req)
 			if err != nil {
 				healthcheck.Err = err
 				return
@@ -243,6 +336,10 @@ func Lighthouse(ctx context.Context, cfg *config.HealthcheckLighthouse) (healthc
 			}
 			timestamp := time.Unix(int64(epoch), 0)
 			age := now.Sub(timestamp)
+			if slot, err := strconv.ParseUint(head.Data.Message.Slot, 10, 64); err == nil {
+				healthcheck.setMetric(MetricHeadSlot, float64(slot))
+			}
+			healthcheck.setMetric(MetricBlockAge, age.Seconds())
 
 			if age > cfg.BlockAgeThreshold {
 				healthcheck.Err = fmt.Errorf("beacon head timestamp '%s' (slot '%s') is too old: %s > %s",
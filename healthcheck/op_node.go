@@ -80,6 +80,17 @@ type opNodeSyncStatus struct {
 	} `json:"result"`
 }
 
+// opNodeRollupConfig is a subset of the rollup configuration of the op-node.
+type opNodeRollupConfig struct {
+	Result struct {
+		// L1ChainID is the chain id of the l1 chain.
+		L1ChainID uint64 `json:"l1_chain_id"`
+
+		// L2ChainID is the chain id of the l2 chain.
+		L2ChainID uint64 `json:"l2_chain_id"`
+	} `json:"result"`
+}
+
 type opNodeL1BlockRef struct {
 	Hash       string `json:"hash"`
 	Number     uint64 `json:"number"`
@@ -102,6 +113,75 @@ type opNodeL2BlockRef struct {
 
 func OpNode(ctx context.Context, cfg *config.HealthcheckOpNode) (healthcheck *Result) {
 	healthcheck = &Result{Source: SourceOpNode}
+	client := newClient(&cfg.Retry, healthcheck)
+
+	{ // optimism_rollupConfig
+		if cfg.ChainID != 0 || cfg.L1ChainID != 0 {
+			// https://docs.optimism.io/builders/node-operators/json-rpc#optimism_rollupconfig
+			// https://github.com/ethereum-optimism/optimism/blob/v1.9.1/op-node/rollup/types.go#L66-L145
+
+			const optimismRollupConfig = `{"jsonrpc":"2.0","method":"optimism_rollupConfig","params":[],"id":0}`
+
+			req, err := http.NewRequestWithContext(
+				ctx,
+				http.MethodPost,
+				cfg.BaseURL,
+				bytes.NewReader([]byte(optimismRollupConfig)),
+			)
+			if err != nil {
+				healthcheck.Err = err
+				return
+			}
+			req.Header.Set("accept", "application/json")
+			req.Header.Set("content-type", "application/json")
+
+			res, err := client.Do(req)
+			if err != nil {
+				healthcheck.Err = err
+				return
+			}
+			defer res.Body.Close()
+
+			body, err := io.ReadAll(res.Body)
+			if err != nil {
+				healthcheck.Err = err
+				return
+			}
+
+			if res.StatusCode != http.StatusOK {
+				healthcheck.Err = fmt.Errorf("unexpected HTTP status '%d': %s",
+					res.StatusCode,
+					string(body),
+				)
+				return
+			}
+
+			var rollupConfig opNodeRollupConfig
+			if err := json.Unmarshal(body, &rollupConfig); err != nil {
+				healthcheck.Err = fmt.Errorf("failed to parse JSON body '%s': %w",
+					string(body),
+					err,
+				)
+				return
+			}
+
+			if cfg.ChainID != 0 && rollupConfig.Result.L2ChainID != cfg.ChainID {
+				healthcheck.Err = fmt.Errorf("unexpected l2 chain id: %d != %d",
+					rollupConfig.Result.L2ChainID,
+					cfg.ChainID,
+				)
+				return
+			}
+
+			if cfg.L1ChainID != 0 && rollupConfig.Result.L1ChainID != cfg.L1ChainID {
+				healthcheck.Err = fmt.Errorf("unexpected l1 chain id: %d != %d",
+					rollupConfig.Result.L1ChainID,
+					cfg.L1ChainID,
+				)
+				return
+			}
+		}
+	}
 
 	{ // optimism_syncStatus
 
@@ -124,7 +204,7 @@ func OpNode(ctx context.Context, cfg *config.HealthcheckOpNode) (healthcheck *Re
 		req.Header.Set("content-type", "application/json")
 
 		now := time.Now()
-		res, err := http.DefaultClient.Do(req)
+		res, err := client.Do(req)
 		if err != nil {
 			healthcheck.Err = err
 			return
@@ -155,6 +235,14 @@ func OpNode(ctx context.Context, cfg *config.HealthcheckOpNode) (healthcheck *Re
 			return
 		}
 
+		healthcheck.setMetric(MetricCurrentL1, float64(status.Result.CurrentL1.Number))
+		healthcheck.setMetric(MetricHeadL1, float64(status.Result.HeadL1.Number))
+		healthcheck.setMetric(MetricL1Distance, float64(status.Result.HeadL1.Number)-float64(status.Result.CurrentL1.Number))
+		healthcheck.setMetric(MetricUnsafeL2, float64(status.Result.UnsafeL2.Number))
+		healthcheck.setMetric(MetricSafeL2, float64(status.Result.SafeL2.Number))
+		healthcheck.setMetric(MetricFinalizedL2, float64(status.Result.FinalizedL2.Number))
+		healthcheck.setMetric(MetricBlockAge, now.Sub(time.Unix(int64(status.Result.UnsafeL2.Time), 0)).Seconds())
+
 		if status.Result.CurrentL1.Number > status.Result.HeadL1.Number {
 			dist := status.Result.CurrentL1.Number - status.Result.HeadL1.Number
 			if dist == 1 {
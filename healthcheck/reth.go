@@ -49,15 +49,164 @@ type rethIsSyncing struct {
 	} `json:"result"`
 }
 
+// rethChainID is the chain id as reported by reth.
+type rethChainID struct {
+	Result string `json:"result"`
+}
+
+// rethNetVersion is the network id as reported by reth.
+type rethNetVersion struct {
+	Result string `json:"result"`
+}
+
 // rethLatestBlock is the latest block as reported by reth
 type rethLatestBlock struct {
 	Result struct {
+		Number    string `json:"number"`
 		Timestamp string `json:"timestamp"`
 	} `json:"result"`
 }
 
-func Reth(ctx context.Context, cfg *config.HealthcheckReth) (healthcheck *Result) {
+func Reth(ctx context.Context, cfg *config.HealthcheckReth, heads *Heads) (healthcheck *Result) {
 	healthcheck = &Result{Source: SourceReth}
+	client := newClient(&cfg.Retry, healthcheck)
+
+	{ // eth_chainId
+		const ethChainId = `{"jsonrpc":"2.0","method":"eth_chainId","params":[],"id":0}`
+
+		if cfg.ChainID != 0 {
+			req, err := http.NewRequestWithContext(
+				ctx,
+				http.MethodPost,
+				cfg.BaseURL,
+				bytes.NewReader([]byte(ethChainId)),
+			)
+			if err != nil {
+				healthcheck.Err = err
+				return
+			}
+			req.Header.Set("accept", "application/json; charset=utf-8")
+			req.Header.Set("content-type", "application/json; charset=utf-8")
+
+			res, err := client.Do(req)
+			if err != nil {
+				healthcheck.Err = err
+				return
+			}
+			defer res.Body.Close()
+
+			body, err := io.ReadAll(res.Body)
+			if err != nil {
+				healthcheck.Err = err
+				return
+			}
+
+			if res.StatusCode != http.StatusOK {
+				healthcheck.Err = fmt.Errorf("unexpected HTTP status '%d': %s",
+					res.StatusCode,
+					string(body),
+				)
+				return
+			}
+
+			var chainID rethChainID
+			if err := json.Unmarshal(body, &chainID); err != nil {
+				healthcheck.Err = fmt.Errorf("failed to parse JSON body '%s': %w",
+					string(body),
+					err,
+				)
+				return
+			}
+
+			id, err := strconv.ParseUint(
+				strings.TrimPrefix(chainID.Result, "0x"),
+				16, 64,
+			)
+			if err != nil {
+				healthcheck.Err = fmt.Errorf("failed to parse hex chain id '%s': %w",
+					chainID.Result,
+					err,
+				)
+				return
+			}
+
+			if id != cfg.ChainID {
+				healthcheck.Err = fmt.Errorf("unexpected chain id: %d != %d",
+					id,
+					cfg.ChainID,
+				)
+				return
+			}
+		}
+	}
+
+	{ // net_version
+		const netVersion = `{"jsonrpc":"2.0","method":"net_version","params":[],"id":0}`
+
+		if cfg.NetVersion != "" {
+			req, err := http.NewRequestWithContext(
+				ctx,
+				http.MethodPost,
+				cfg.BaseURL,
+				bytes.NewReader([]byte(netVersion)),
+			)
+			if err != nil {
+				healthcheck.Err = err
+				return
+			}
+			req.Header.Set("accept", "application/json; charset=utf-8")
+			req.Header.Set("content-type", "application/json; charset=utf-8")
+
+			res, err := client.Do(req)
+			if err != nil {
+				healthcheck.Err = err
+				return
+			}
+			defer res.Body.Close()
+
+			body, err := io.ReadAll(res.Body)
+			if err != nil {
+				healthcheck.Err = err
+				return
+			}
+
+			if res.StatusCode != http.StatusOK {
+				healthcheck.Err = fmt.Errorf("unexpected HTTP status '%d': %s",
+					res.StatusCode,
+					string(body),
+				)
+				return
+			}
+
+			var version rethNetVersion
+			if err := json.Unmarshal(body, &version); err != nil {
+				healthcheck.Err = fmt.Errorf("failed to parse JSON body '%s': %w",
+					string(body),
+					err,
+				)
+				return
+			}
+
+			if version.Result != cfg.NetVersion {
+				healthcheck.Err = fmt.Errorf("unexpected network id: '%s' != '%s'",
+					version.Result,
+					cfg.NetVersion,
+				)
+				return
+			}
+		}
+	}
+
+	{ // engine api
+		if cfg.Engine.BaseURL != "" {
+			if err := engine(ctx, client, &cfg.Engine); err != nil {
+				healthcheck.Err = fmt.Errorf("engine api check failed: %w",
+					err,
+				)
+				return
+			}
+		}
+	}
 
 	{ // eth_syncing
 
@@ -79,7 +228,7 @@ func Reth(ctx context.Context, cfg *config.HealthcheckReth) (healthcheck *Result
 		req.Header.Set("accept", "application/json; charset=utf-8")
 		req.Header.Set("content-type", "application/json; charset=utf-8")
 
-		res, err := http.DefaultClient.Do(req)
+		res, err := client.Do(req)
 		if err != nil {
 			healthcheck.Err = err
 			return
@@ -110,6 +259,8 @@ func Reth(ctx context.Context, cfg *config.HealthcheckReth) (healthcheck *Result
 				)
 				return
 			}
+			healthcheck.setHexMetric(MetricSyncCurrent, status.Result.CurrentBlock)
+			healthcheck.setHexMetric(MetricSyncHighest, status.Result.HighestBlock)
 			stages := make([]string, 0, len(status.Result.Stages))
 			for idx, stage := range status.Result.Stages {
 				stages = append(stages, fmt.Sprintf("%s(%d)=%s", stage.Name, idx, stage.Block))
@@ -127,10 +278,36 @@ func Reth(ctx context.Context, cfg *config.HealthcheckReth) (healthcheck *Result
 		}
 	}
 
+	{ // eth_subscribe("newHeads")
+		if heads != nil {
+			head, err := heads.Latest()
+			if err != nil {
+				healthcheck.Err = err
+				return
+			}
+
+			if cfg.BlockAgeThreshold != 0 {
+				age := time.Since(head.Timestamp)
+				healthcheck.setHexMetric(MetricLatestBlock, head.Number)
+				healthcheck.setMetric(MetricBlockAge, age.Seconds())
+
+				if age > cfg.BlockAgeThreshold {
+					healthcheck.Err = fmt.Errorf("latest block's (number '%s') timestamp '%d' is too old: %s > %s",
+						head.Number,
+						head.Timestamp.Unix(),
+						age,
+						cfg.BlockAgeThreshold,
+					)
+					return
+				}
+			}
+		}
+	}
+
 	{ // eth_getBlockByNumber
 		const ethGetBlockByNumber = `{"jsonrpc":"2.0","method":"eth_getBlockByNumber","params":["latest",false],"id":0}`
 
-		if cfg.BlockAgeThreshold != 0 {
+		if cfg.BlockAgeThreshold != 0 && heads == nil {
 			req, err := http.NewRequestWithContext(
 				ctx,
 				http.MethodPost,
@@ -145,7 +322,7 @@ func Reth(ctx context.Context, cfg *config.HealthcheckReth) (healthcheck *Result
 			req.Header.Set("content-type", "application/json")
 
 			now := time.Now()
-			res, err := http.DefaultClient.Do(req)
+			res, err := client.Do(req)
 			if err != nil {
 				healthcheck.Err = err
 				return
@@ -189,6 +366,8 @@ func Reth(ctx context.Context, cfg *config.HealthcheckReth) (healthcheck *Result
 
 			timestamp := time.Unix(epoch, 0)
 			age := now.Sub(timestamp)
+			healthcheck.setHexMetric(MetricLatestBlock, latestBlock.Result.Number)
+			healthcheck.setMetric(MetricBlockAge, age.Seconds())
 
 			if age > cfg.BlockAgeThreshold {
 				healthcheck.Err = fmt.Errorf("latest block's timestamp '%s' is too old: %s > %s",
@@ -201,6 +380,32 @@ func Reth(ctx context.Context, cfg *config.HealthcheckReth) (healthcheck *Result
 		}
 	}
 
+	{ // archive
+		if cfg.Archive.Enabled {
+			if err := archive(ctx, client, cfg.BaseURL, &cfg.Archive); err != nil {
+				healthcheck.Err = fmt.Errorf("archive check failed: %w",
+					err,
+				)
+				return
+			}
+		}
+	}
+
+	{ // smoke tests
+		if len(cfg.SmokeTests) > 0 {
+			errs, wrns := smoke(ctx, client, cfg.BaseURL, cfg.SmokeTests)
+			if errs != nil {
+				healthcheck.Err = errors.Join(errs, wrns)
+				return
+			}
+			if wrns != nil {
+				healthcheck.Ok = true
+				healthcheck.Err = wrns
+				return
+			}
+		}
+	}
+
 	healthcheck.Ok = true
 	return
 }
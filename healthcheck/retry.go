@@ -0,0 +1,178 @@
+package healthcheck
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strings"
+	"syscall"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/flashbots/node-healthchecker/config"
+	"github.com/flashbots/node-healthchecker/tracing"
+)
+
+// client performs the HTTP requests of a healthcheck, retrying the ones that
+// failed transiently with exponential backoff (and jitter) for as long as the
+// deadline of the healthcheck allows.
+type client struct {
+	cfg    *config.HealthcheckRetry
+	result *Result
+}
+
+func newClient(cfg *config.HealthcheckRetry, result *Result) *client {
+	return &client{
+		cfg:    cfg,
+		result: result,
+	}
+}
+
+func (c *client) Do(req *http.Request) (res *http.Response, err error) {
+	ctx, span := tracing.Start(req.Context(), spanName(req),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("http.request.method", req.Method),
+			attribute.String("url.full", req.URL.Redacted()),
+		),
+	)
+	req = req.WithContext(ctx)
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+	var attempt uint
+	defer func() {
+		span.SetAttributes(attribute.Int("http.request.attempts", int(attempt)))
+		if res != nil {
+			span.SetAttributes(attribute.Int("http.response.status_code", res.StatusCode))
+		}
+		switch {
+		case err != nil:
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		case res.StatusCode >= 400:
+			span.SetStatus(codes.Error, http.StatusText(res.StatusCode))
+		}
+		span.End()
+	}()
+
+	attempts := max(c.cfg.Attempts, 1)
+	backoff := c.cfg.Backoff
+
+	for attempt = 1; ; attempt++ {
+		res, err = http.DefaultClient.Do(c.prepare(req, attempt))
+		if attempt >= attempts || !retryable(req.Context(), res, err) {
+			return c.annotate(res, err, attempt)
+		}
+
+		delay := backoff/2 + rand.N(backoff/2+1) // "equal" jitter
+		if deadline, ok := req.Context().Deadline(); ok && time.Until(deadline) < delay {
+			return c.annotate(res, err, attempt) // out of budget
+		}
+
+		if res != nil {
+			_, _ = io.Copy(io.Discard, io.LimitReader(res.Body, 4096))
+			res.Body.Close()
+		}
+
+		select {
+		case <-req.Context().Done():
+			return c.annotate(nil, req.Context().Err(), attempt)
+		case <-time.After(delay):
+		}
+
+		c.result.Retries++
+		backoff *= 2
+	}
+}
+
+// spanName returns the json-rpc method of the request (for POST), or its path
+// (for GET), e.g. `eth_syncing` or `lighthouse/syncing`.
+func spanName(req *http.Request) string {
+	if req.Method == http.MethodPost && req.GetBody != nil {
+		if body, err := req.GetBody(); err == nil {
+			defer body.Close()
+			var rpc struct {
+				Method string `json:"method"`
+			}
+			if err := json.NewDecoder(body).Decode(&rpc); err == nil && rpc.Method != "" {
+				return rpc.Method
+			}
+		}
+	}
+	if path := strings.TrimPrefix(req.URL.Path, "/"); path != "" {
+		return path
+	}
+	return req.Method
+}
+
+// prepare rewinds the body of the request for a repeated attempt.
+func (c *client) prepare(req *http.Request, attempt uint) *http.Request {
+	if attempt == 1 || req.GetBody == nil {
+		return req
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return req
+	}
+	clone := req.Clone(req.Context())
+	clone.Body = body
+	return clone
+}
+
+// annotate adds the count of attempts to the failure of a retried request
+// (turning the unsuccessful HTTP status into an error, so that it's reported
+// as retried too).
+func (c *client) annotate(res *http.Response, err error, attempt uint) (*http.Response, error) {
+	if attempt == 1 {
+		return res, err
+	}
+
+	if err == nil && res.StatusCode >= 400 {
+		defer res.Body.Close()
+		body, _ := io.ReadAll(io.LimitReader(res.Body, 4096))
+		err = fmt.Errorf("unexpected HTTP status '%d': %s",
+			res.StatusCode,
+			string(body),
+		)
+		res = nil
+	}
+
+	if err == nil {
+		return res, nil
+	}
+	return res, fmt.Errorf("%w (after %d attempts)",
+		err,
+		attempt,
+	)
+}
+
+// retryable returns true for the failures that might go away with a retry.
+func retryable(ctx context.Context, res *http.Response, err error) bool {
+	if ctx.Err() != nil {
+		return false // the deadline of the healthcheck itself is exceeded
+	}
+
+	if err == nil {
+		return res.StatusCode >= 500
+	}
+
+	if errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
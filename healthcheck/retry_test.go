@@ -0,0 +1,154 @@
+package healthcheck
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/flashbots/node-healthchecker/config"
+)
+
+// newFlakyServer returns the server that responds with the status of the
+// respective attempt (and with the last one once they run out), along with
+// the counter of the attempts.
+func newFlakyServer(t *testing.T, statuses ...int) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempt := int(attempts.Add(1))
+		if body, _ := io.ReadAll(r.Body); string(body) != `{"method":"eth_syncing"}` {
+			http.Error(w, "unexpected body: "+string(body), http.StatusBadRequest)
+			return
+		}
+		status := statuses[min(attempt, len(statuses))-1]
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(http.StatusText(status)))
+	}))
+	t.Cleanup(server.Close)
+
+	return server, &attempts
+}
+
+func newRequest(t *testing.T, ctx context.Context, url string) *http.Request {
+	t.Helper()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, strings.NewReader(`{"method":"eth_syncing"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return req
+}
+
+func TestClientRetriesUntilSuccess(t *testing.T) {
+	server, attempts := newFlakyServer(t,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusOK,
+	)
+
+	result := &Result{}
+	c := newClient(&config.HealthcheckRetry{Attempts: 5, Backoff: time.Millisecond}, result)
+
+	res, err := c.Do(newRequest(t, context.Background(), server.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", res.StatusCode)
+	}
+	if count := attempts.Load(); count != 3 {
+		t.Fatalf("expected 3 attempts, got %d", count)
+	}
+	if result.Retries != 2 {
+		t.Fatalf("expected 2 retries, got %d", result.Retries)
+	}
+}
+
+func TestClientReportsAttempts(t *testing.T) {
+	server, attempts := newFlakyServer(t,
+		http.StatusInternalServerError,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+	)
+
+	result := &Result{}
+	c := newClient(&config.HealthcheckRetry{Attempts: 3, Backoff: time.Millisecond}, result)
+
+	res, err := c.Do(newRequest(t, context.Background(), server.URL))
+	if err == nil {
+		res.Body.Close()
+		t.Fatalf("expected an error, got status %d", res.StatusCode)
+	}
+	if res != nil {
+		t.Errorf("expected no response along with the error")
+	}
+
+	if expected := "unexpected HTTP status '503': Service Unavailable (after 3 attempts)"; err.Error() != expected {
+		t.Fatalf("expected error '%s', got '%s'", expected, err)
+	}
+	if count := attempts.Load(); count != 3 {
+		t.Fatalf("expected 3 attempts, got %d", count)
+	}
+	if result.Retries != 2 {
+		t.Fatalf("expected 2 retries, got %d", result.Retries)
+	}
+}
+
+func TestClientDoesNotRetryClientErrors(t *testing.T) {
+	server, attempts := newFlakyServer(t,
+		http.StatusNotFound,
+	)
+
+	result := &Result{}
+	c := newClient(&config.HealthcheckRetry{Attempts: 3, Backoff: time.Millisecond}, result)
+
+	res, err := c.Do(newRequest(t, context.Background(), server.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected status 404 (to be handled by the healthcheck), got %d", res.StatusCode)
+	}
+	if count := attempts.Load(); count != 1 {
+		t.Fatalf("expected 1 attempt, got %d", count)
+	}
+}
+
+func TestClientRespectsDeadline(t *testing.T) {
+	server, attempts := newFlakyServer(t,
+		http.StatusServiceUnavailable,
+	)
+
+	result := &Result{}
+	c := newClient(&config.HealthcheckRetry{Attempts: 10, Backoff: time.Second}, result)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	res, err := c.Do(newRequest(t, ctx, server.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Fatalf("expected no backoff beyond the deadline, took %s", elapsed)
+	}
+	if res.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("expected status 503, got %d", res.StatusCode)
+	}
+	if count := attempts.Load(); count != 1 {
+		t.Fatalf("expected 1 attempt, got %d", count)
+	}
+}
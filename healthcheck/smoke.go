@@ -0,0 +1,173 @@
+package healthcheck
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptrace"
+	"reflect"
+	"time"
+
+	"github.com/flashbots/node-healthchecker/config"
+)
+
+// smokeResponse is the generic JSON-RPC response.
+type smokeResponse struct {
+	Error *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// smoke runs the smoke tests and returns the failures grouped by severity.
+func smoke(ctx context.Context, client *client, baseURL string, tests []*config.HealthcheckSmokeTest) (errs, wrns error) {
+	_errs := make([]error, 0)
+	_wrns := make([]error, 0)
+
+	for _, test := range tests {
+		if err := smokeTest(ctx, client, baseURL, test); err != nil {
+			err = fmt.Errorf("smoke test '%s' failed: %w",
+				test.Name,
+				err,
+			)
+			switch test.Severity {
+			case config.SeverityWarning:
+				_wrns = append(_wrns, err)
+			default:
+				_errs = append(_errs, err)
+			}
+		}
+	}
+
+	return errors.Join(_errs...), errors.Join(_wrns...)
+}
+
+func smokeTest(ctx context.Context, client *client, baseURL string, test *config.HealthcheckSmokeTest) error {
+	payload, err := json.Marshal(map[string]any{
+		"jsonrpc": "2.0",
+		"method":  test.Method,
+		"params":  test.Params,
+		"id":      0,
+	})
+	if err != nil {
+		return err
+	}
+
+	var start time.Time
+	if test.MaxLatency != 0 { // time the last attempt only (not the retries)
+		ctx = httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+			GetConn: func(string) { start = time.Now() },
+		})
+	}
+
+	req, err := http.NewRequestWithContext(
+		ctx,
+		http.MethodPost,
+		baseURL,
+		bytes.NewReader(payload),
+	)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("accept", "application/json")
+	req.Header.Set("content-type", "application/json")
+
+	res, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+	latency := time.Since(start)
+
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected HTTP status '%d': %s",
+			res.StatusCode,
+			string(body),
+		)
+	}
+
+	var response smokeResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return fmt.Errorf("failed to parse JSON body '%s': %w",
+			string(body),
+			err,
+		)
+	}
+	if response.Error != nil {
+		return fmt.Errorf("error response (code: %d): %s",
+			response.Error.Code,
+			response.Error.Message,
+		)
+	}
+
+	if test.MaxLatency != 0 && latency > test.MaxLatency {
+		return fmt.Errorf("too slow: %s > %s",
+			latency,
+			test.MaxLatency,
+		)
+	}
+
+	if len(test.Equals) == 0 && test.Regexp == nil {
+		return nil
+	}
+
+	var doc any
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return fmt.Errorf("failed to parse JSON body '%s': %w",
+			string(body),
+			err,
+		)
+	}
+	actual, err := test.JSONPath.Lookup(doc)
+	if err != nil {
+		return err
+	}
+
+	if len(test.Equals) > 0 {
+		var expected any
+		if err := json.Unmarshal(test.Equals, &expected); err != nil {
+			return err
+		}
+		if !reflect.DeepEqual(actual, expected) {
+			return fmt.Errorf("unexpected value at '%s': %s != %s",
+				test.JSONPath,
+				smokeValue(actual),
+				smokeValue(expected),
+			)
+		}
+	}
+
+	if test.Regexp != nil {
+		if str := smokeValue(actual); !test.Regexp.MatchString(str) {
+			return fmt.Errorf("value at '%s' does not match '%s': %s",
+				test.JSONPath,
+				test.Regexp,
+				str,
+			)
+		}
+	}
+
+	return nil
+}
+
+// smokeValue returns the string representation of the value (as-is for the
+// strings and JSON-encoded for everything else).
+func smokeValue(value any) string {
+	if str, ok := value.(string); ok {
+		return str
+	}
+	str, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(str)
+}
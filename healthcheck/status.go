@@ -0,0 +1,47 @@
+package healthcheck
+
+import "time"
+
+type Status string
+
+const (
+	StatusUnknown Status = "unknown"
+	StatusOk      Status = "ok"
+	StatusWarning Status = "warning"
+	StatusError   Status = "error"
+
+	// StatusSkipped is reported for the results of the healthchecks that were
+	// not run (it never becomes the status of the source).
+	StatusSkipped Status = "skipped"
+)
+
+// Status returns the status that the result translates into.
+func (r *Result) Status() Status {
+	switch {
+	case r.Skipped:
+		return StatusSkipped
+	case !r.Ok:
+		return StatusError
+	case r.Err != nil:
+		return StatusWarning
+	default:
+		return StatusOk
+	}
+}
+
+// Message returns the human-readable details of the result (if any).
+func (r *Result) Message() string {
+	if r.Err == nil {
+		return ""
+	}
+	return r.Err.Error()
+}
+
+// Transition is a change of the status of a healthcheck source.
+type Transition struct {
+	Source    string    `json:"source"`
+	From      Status    `json:"from"`
+	To        Status    `json:"to"`
+	Message   string    `json:"message"`
+	Timestamp time.Time `json:"timestamp"`
+}
@@ -7,9 +7,15 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 
 	"github.com/flashbots/node-healthchecker/logutils"
+	"github.com/flashbots/node-healthchecker/tracing"
 )
 
 func Middleware(logger *zap.Logger, next http.Handler) http.Handler {
@@ -18,8 +24,29 @@ func Middleware(logger *zap.Logger, next http.Handler) http.Handler {
 		_uuid := [16]byte(uuid.New())
 		httpRequestID := base64.RawStdEncoding.EncodeToString(_uuid[:])
 
-		l := logger.With(
+		// Continue the trace of the caller (if there's one)
+		ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+		ctx, span := tracing.Start(ctx, r.Method+" "+r.URL.EscapedPath(),
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				attribute.String("http.request.method", r.Method),
+				attribute.String("url.path", r.URL.EscapedPath()),
+			),
+		)
+		defer span.End()
+		r = r.WithContext(ctx)
+
+		fields := []zap.Field{
 			zap.String("httpRequestID", httpRequestID),
+		}
+		if sc := span.SpanContext(); sc.IsValid() {
+			fields = append(fields,
+				zap.String("traceID", sc.TraceID().String()),
+				zap.String("spanID", sc.SpanID().String()),
+			)
+		}
+
+		l := logger.With(fields...).With(
 			zap.String("logType", "activity"),
 		)
 		r = logutils.RequestWithLogger(r, l)
@@ -45,12 +72,16 @@ func Middleware(logger *zap.Logger, next http.Handler) http.Handler {
 		wrapped := wrapResponseWriter(w)
 		next.ServeHTTP(wrapped, r)
 
+		span.SetAttributes(attribute.Int("http.response.status_code", wrapped.Status()))
+		if wrapped.Status() >= 500 {
+			span.SetStatus(codes.Error, http.StatusText(wrapped.Status()))
+		}
+
 		// Passing request stats both in-message (for the human reader)
 		// as well as inside the structured log (for the machine parser)
-		logger.Debug(fmt.Sprintf("%s %s %d", r.Method, r.URL.EscapedPath(), wrapped.Status()),
+		logger.With(fields...).Debug(fmt.Sprintf("%s %s %d", r.Method, r.URL.EscapedPath(), wrapped.Status()),
 			zap.Int("durationMs", int(time.Since(start).Milliseconds())),
 			zap.Int("status", wrapped.Status()),
-			zap.String("httpRequestID", httpRequestID),
 			zap.String("logType", "access"),
 			zap.String("method", r.Method),
 			zap.String("path", r.URL.EscapedPath()),
@@ -0,0 +1,129 @@
+// Package kubernetes patches a custom condition of the healthchecker's own pod
+// via kubernetes api, so that the pod's `readinessGates` can depend on it.
+//
+// See: https://kubernetes.io/docs/concepts/workloads/pods/pod-lifecycle/#pod-readiness-gate
+package kubernetes
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/flashbots/node-healthchecker/config"
+)
+
+// Condition is the state of the custom pod condition.
+type Condition struct {
+	Ready   bool
+	Reason  string
+	Message string
+	Since   time.Time
+}
+
+// Patcher updates the condition of the pod.
+type Patcher struct {
+	cfg *config.Kubernetes
+
+	client *http.Client
+}
+
+type podCondition struct {
+	Type               string `json:"type"`
+	Status             string `json:"status"`
+	Reason             string `json:"reason,omitempty"`
+	Message            string `json:"message,omitempty"`
+	LastProbeTime      string `json:"lastProbeTime,omitempty"`
+	LastTransitionTime string `json:"lastTransitionTime,omitempty"`
+}
+
+type podStatusPatch struct {
+	Status struct {
+		Conditions []podCondition `json:"conditions"`
+	} `json:"status"`
+}
+
+func New(cfg *config.Kubernetes) *Patcher {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if cfg.TLS != nil {
+		transport.TLSClientConfig = cfg.TLS
+	}
+
+	return &Patcher{
+		cfg: cfg,
+		client: &http.Client{
+			Timeout:   cfg.Timeout,
+			Transport: transport,
+		},
+	}
+}
+
+// Patch sets the condition of the pod (the other conditions are left intact,
+// since strategic merge patch merges them by type).
+func (p *Patcher) Patch(ctx context.Context, condition Condition) error {
+	status := "False"
+	if condition.Ready {
+		status = "True"
+	}
+
+	patch := &podStatusPatch{}
+	patch.Status.Conditions = []podCondition{{
+		Type:               p.cfg.PodCondition,
+		Status:             status,
+		Reason:             condition.Reason,
+		Message:            condition.Message,
+		LastProbeTime:      time.Now().UTC().Format(time.RFC3339),
+		LastTransitionTime: condition.Since.UTC().Format(time.RFC3339),
+	}}
+	body, err := json.Marshal(patch)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(
+		ctx,
+		http.MethodPatch,
+		fmt.Sprintf("%s/api/v1/namespaces/%s/pods/%s/status",
+			strings.TrimSuffix(p.cfg.APIServer, "/"),
+			url.PathEscape(p.cfg.Namespace),
+			url.PathEscape(p.cfg.PodName),
+		),
+		bytes.NewReader(body),
+	)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("accept", "application/json")
+	req.Header.Set("content-type", "application/strategic-merge-patch+json")
+
+	if p.cfg.TokenFile != "" {
+		// the projected service-account tokens are rotated, hence re-reading
+		token, err := os.ReadFile(p.cfg.TokenFile)
+		if err != nil {
+			return fmt.Errorf("failed to read kubernetes token: %w",
+				err,
+			)
+		}
+		req.Header.Set("authorization", "Bearer "+strings.TrimSpace(string(token)))
+	}
+
+	res, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+		return fmt.Errorf("failed to patch condition '%s' of pod '%s/%s': unexpected http status %d: %s",
+			p.cfg.PodCondition, p.cfg.Namespace, p.cfg.PodName, res.StatusCode, strings.TrimSpace(string(msg)),
+		)
+	}
+	return nil
+}
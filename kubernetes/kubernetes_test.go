@@ -0,0 +1,172 @@
+package kubernetes
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/flashbots/node-healthchecker/config"
+)
+
+type fakeRequest struct {
+	method        string
+	path          string
+	contentType   string
+	authorization string
+	body          podStatusPatch
+}
+
+// fakeAPIServer records the requests it receives.
+type fakeAPIServer struct {
+	*httptest.Server
+
+	requests []fakeRequest
+	mx       sync.Mutex
+}
+
+func newFakeAPIServer(t *testing.T) *fakeAPIServer {
+	t.Helper()
+
+	f := &fakeAPIServer{}
+	f.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		req := fakeRequest{
+			method:        r.Method,
+			path:          r.URL.Path,
+			contentType:   r.Header.Get("content-type"),
+			authorization: r.Header.Get("authorization"),
+		}
+		if err := json.Unmarshal(body, &req.body); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		f.mx.Lock()
+		f.requests = append(f.requests, req)
+		f.mx.Unlock()
+
+		w.Header().Set("content-type", "application/json")
+		_, _ = w.Write([]byte("{}"))
+	}))
+	t.Cleanup(f.Close)
+
+	return f
+}
+
+func (f *fakeAPIServer) received() []fakeRequest {
+	f.mx.Lock()
+	defer f.mx.Unlock()
+
+	return append([]fakeRequest{}, f.requests...)
+}
+
+func TestPatch(t *testing.T) {
+	api := newFakeAPIServer(t)
+
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(tokenFile, []byte("first\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	p := New(&config.Kubernetes{
+		APIServer:    api.URL,
+		Namespace:    "default",
+		PodCondition: "example.com/node-healthy",
+		PodName:      "node-0",
+		Timeout:      time.Second,
+		TokenFile:    tokenFile,
+	})
+
+	since := time.Date(2024, 10, 18, 16, 43, 3, 0, time.UTC)
+	if err := p.Patch(context.Background(), Condition{
+		Ready:   false,
+		Reason:  "HealthcheckError",
+		Message: "geth: still syncing",
+		Since:   since,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// the token is rotated
+	if err := os.WriteFile(tokenFile, []byte("second\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Patch(context.Background(), Condition{
+		Ready:  true,
+		Reason: "HealthcheckOk",
+		Since:  since,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	requests := api.received()
+	if len(requests) != 2 {
+		t.Fatalf("expected 2 requests, got %d", len(requests))
+	}
+
+	for idx, token := range []string{"first", "second"} {
+		req := requests[idx]
+		if req.method != http.MethodPatch {
+			t.Errorf("request %d: expected method PATCH, got %s", idx, req.method)
+		}
+		if req.path != "/api/v1/namespaces/default/pods/node-0/status" {
+			t.Errorf("request %d: unexpected path %s", idx, req.path)
+		}
+		if req.contentType != "application/strategic-merge-patch+json" {
+			t.Errorf("request %d: unexpected content type %s", idx, req.contentType)
+		}
+		if req.authorization != "Bearer "+token {
+			t.Errorf("request %d: expected bearer token '%s', got '%s'", idx, token, req.authorization)
+		}
+		if len(req.body.Status.Conditions) != 1 {
+			t.Fatalf("request %d: expected 1 condition, got %d", idx, len(req.body.Status.Conditions))
+		}
+	}
+
+	first := requests[0].body.Status.Conditions[0]
+	if first.Type != "example.com/node-healthy" ||
+		first.Status != "False" ||
+		first.Reason != "HealthcheckError" ||
+		first.Message != "geth: still syncing" ||
+		first.LastTransitionTime != "2024-10-18T16:43:03Z" {
+		t.Errorf("unexpected condition: %+v", first)
+	}
+	if _, err := time.Parse(time.RFC3339, first.LastProbeTime); err != nil {
+		t.Errorf("invalid last probe time: %v", err)
+	}
+
+	second := requests[1].body.Status.Conditions[0]
+	if second.Status != "True" || second.Reason != "HealthcheckOk" || second.Message != "" {
+		t.Errorf("unexpected condition: %+v", second)
+	}
+}
+
+func TestPatchFailure(t *testing.T) {
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "pods \"node-0\" is forbidden", http.StatusForbidden)
+	}))
+	defer api.Close()
+
+	p := New(&config.Kubernetes{
+		APIServer:    api.URL,
+		Namespace:    "default",
+		PodCondition: "example.com/node-healthy",
+		PodName:      "node-0",
+		Timeout:      time.Second,
+	})
+
+	if err := p.Patch(context.Background(), Condition{Ready: true}); err == nil {
+		t.Fatal("expected an error")
+	}
+}
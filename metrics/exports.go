@@ -8,5 +8,20 @@ var (
 	HealthchecksFlipCount otelapi.Int64Counter
 	HealthchecksNokCount  otelapi.Int64Counter
 	HealthchecksOkCount   otelapi.Int64Counter
+	HealthchecksRetries   otelapi.Int64Counter
 	HealthcheckUp         otelapi.Int64Gauge
+
+	HealthcheckLatency otelapi.Float64Histogram
+
+	SLOAvailability         otelapi.Float64Gauge
+	SLOBurnRate             otelapi.Float64Gauge
+	SLOCoverage             otelapi.Float64Gauge
+	SLOErrorBudgetRemaining otelapi.Float64Gauge
+	SLOTarget               otelapi.Float64Gauge
+
+	// NodeState reports the numeric observations collected by the latest
+	// healthcheck of every source.
+	NodeState = &nodeState{
+		sources: make(map[string]map[string]float64),
+	}
 )
@@ -2,11 +2,17 @@ package metrics
 
 import (
 	"context"
+	"maps"
+	"sync"
 
+	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/exporters/prometheus"
 	otelapi "go.opentelemetry.io/otel/metric"
 	"go.opentelemetry.io/otel/sdk/metric"
-	"go.opentelemetry.io/otel/sdk/resource"
+
+	"github.com/flashbots/node-healthchecker/config"
+	"github.com/flashbots/node-healthchecker/healthcheck"
+	"github.com/flashbots/node-healthchecker/utils"
 )
 
 const (
@@ -14,16 +20,39 @@ const (
 )
 
 var (
-	meter otelapi.Meter
+	meter    otelapi.Meter
+	provider *metric.MeterProvider
 )
 
-func Setup(ctx context.Context) error {
+var nodeStateDescriptions = map[string]string{
+	healthcheck.MetricBlockAge:     "age of the latest block (as seen by the healthcheck)",
+	healthcheck.MetricCurrentL1:    "l1 block number that op-node has derived up to",
+	healthcheck.MetricFinalizedL2:  "finalized l2 block number (as per op-node)",
+	healthcheck.MetricHeadL1:       "l1 head block number (as per op-node)",
+	healthcheck.MetricHeadSlot:     "slot of the beacon head block",
+	healthcheck.MetricL1Distance:   "distance between l1 head and the l1 block that op-node has derived up to",
+	healthcheck.MetricLatestBlock:  "latest block number of the execution client",
+	healthcheck.MetricSafeL2:       "safe l2 block number (as per op-node)",
+	healthcheck.MetricSyncCurrent:  "current block number of the syncing execution client",
+	healthcheck.MetricSyncDistance: "count of slots the consensus client is yet to sync",
+	healthcheck.MetricSyncHighest:  "highest known block number of the syncing execution client",
+	healthcheck.MetricUnsafeL2:     "unsafe l2 block number (as per op-node)",
+}
+
+func Setup(ctx context.Context, cfg *config.Metrics) error {
+	if err := setupMeter(ctx, cfg); err != nil { // must come first
+		return err
+	}
+
 	for _, setup := range []func(context.Context) error{
-		setupMeter, // must come first
 		setupHealthchecksFlipCount,
 		setupHealthchecksNokCount,
 		setupHealthchecksOkCount,
+		setupHealthchecksRetries,
 		setupHealthchecksUp,
+		setupHealthcheckLatency,
+		setupNodeState,
+		setupSLO,
 	} {
 		if err := setup(ctx); err != nil {
 			return err
@@ -33,25 +62,47 @@ func Setup(ctx context.Context) error {
 	return nil
 }
 
-func setupMeter(ctx context.Context) error {
-	res, err := resource.New(ctx)
-	if err != nil {
-		return err
+// Shutdown flushes the metrics that are yet to be pushed to the otlp
+// collector.
+func Shutdown(ctx context.Context) error {
+	if provider == nil {
+		return nil
 	}
+	return provider.Shutdown(ctx)
+}
 
-	exporter, err := prometheus.New(
-		prometheus.WithNamespace(metricsNamespace),
-		prometheus.WithoutScopeInfo(),
-	)
+func setupMeter(ctx context.Context, cfg *config.Metrics) error {
+	res, err := utils.OtelResource(ctx, metricsNamespace, cfg.ResourceAttributes)
 	if err != nil {
 		return err
 	}
 
-	provider := metric.NewMeterProvider(
-		metric.WithReader(exporter),
+	opts := []metric.Option{
 		metric.WithResource(res),
-	)
+	}
 
+	if cfg.Prometheus {
+		exporter, err := prometheus.New(
+			prometheus.WithNamespace(metricsNamespace),
+			prometheus.WithoutScopeInfo(),
+		)
+		if err != nil {
+			return err
+		}
+		opts = append(opts, metric.WithReader(exporter))
+	}
+
+	if cfg.OTLP.Endpoint != "" {
+		exporter, err := newOTLPExporter(ctx, &cfg.OTLP)
+		if err != nil {
+			return err
+		}
+		opts = append(opts, metric.WithReader(metric.NewPeriodicReader(exporter,
+			metric.WithInterval(cfg.OTLP.Interval),
+		)))
+	}
+
+	provider = metric.NewMeterProvider(opts...)
 	meter = provider.Meter(metricsNamespace)
 
 	return nil
@@ -90,6 +141,17 @@ func setupHealthchecksOkCount(ctx context.Context) error {
 	return nil
 }
 
+func setupHealthchecksRetries(ctx context.Context) error {
+	m, err := meter.Int64Counter("healthcheck_retry_count",
+		otelapi.WithDescription("count of retried healthcheck requests"),
+	)
+	if err != nil {
+		return err
+	}
+	HealthchecksRetries = m
+	return nil
+}
+
 func setupHealthchecksUp(ctx context.Context) error {
 	m, err := meter.Int64Gauge("healthcheck_up",
 		otelapi.WithDescription("healthcheck status"),
@@ -100,3 +162,92 @@ func setupHealthchecksUp(ctx context.Context) error {
 	HealthcheckUp = m
 	return nil
 }
+
+func setupHealthcheckLatency(ctx context.Context) error {
+	m, err := meter.Float64Histogram("healthcheck_latency",
+		otelapi.WithDescription("duration of healthchecks"),
+		otelapi.WithUnit("s"),
+		otelapi.WithExplicitBucketBoundaries(.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10),
+	)
+	if err != nil {
+		return err
+	}
+	HealthcheckLatency = m
+	return nil
+}
+
+// nodeState holds the observations of the latest healthcheck of every source,
+// so that the ones missing from it are not exported (as opposed to the plain
+// gauges that would keep reporting their last value forever).
+type nodeState struct {
+	sources map[string]map[string]float64
+
+	mx sync.Mutex
+}
+
+// Set replaces the observations of the source.
+func (n *nodeState) Set(source string, observations map[string]float64) {
+	n.mx.Lock()
+	defer n.mx.Unlock()
+
+	n.sources[source] = maps.Clone(observations)
+}
+
+func (n *nodeState) observe(gauges map[string]otelapi.Float64ObservableGauge) otelapi.Callback {
+	return func(_ context.Context, o otelapi.Observer) error {
+		n.mx.Lock()
+		defer n.mx.Unlock()
+
+		for source, observations := range n.sources {
+			attrs := otelapi.WithAttributes(
+				attribute.KeyValue{Key: "healthcheck_source", Value: attribute.StringValue(source)},
+			)
+			for name, value := range observations {
+				if gauge, known := gauges[name]; known {
+					o.ObserveFloat64(gauge, value, attrs)
+				}
+			}
+		}
+		return nil
+	}
+}
+
+func setupNodeState(ctx context.Context) error {
+	gauges := make(map[string]otelapi.Float64ObservableGauge, len(nodeStateDescriptions))
+	instruments := make([]otelapi.Observable, 0, len(nodeStateDescriptions))
+	for name, description := range nodeStateDescriptions {
+		m, err := meter.Float64ObservableGauge("node_"+name,
+			otelapi.WithDescription(description),
+		)
+		if err != nil {
+			return err
+		}
+		gauges[name] = m
+		instruments = append(instruments, m)
+	}
+	_, err := meter.RegisterCallback(NodeState.observe(gauges), instruments...)
+	return err
+}
+
+func setupSLO(ctx context.Context) error {
+	for _, gauge := range []struct {
+		metric      *otelapi.Float64Gauge
+		name        string
+		description string
+	}{
+		{&SLOAvailability, "slo_availability", "ratio of time the source was available (ok or warning) within the slo window"},
+		{&SLOBurnRate, "slo_burn_rate", "rate at which the error budget of the slo is being spent within the burn-rate window (1 means exactly on budget)"},
+		{&SLOCoverage, "slo_coverage", "ratio of the slo window for which the status of the source is known (the availability is computed over this part only)"},
+		{&SLOErrorBudgetRemaining, "slo_error_budget_remaining", "ratio of the error budget of the slo that is yet to be spent within the slo window"},
+		{&SLOTarget, "slo_target", "target availability ratio of the slo"},
+	} {
+		m, err := meter.Float64Gauge(gauge.name,
+			otelapi.WithDescription(gauge.description),
+		)
+		if err != nil {
+			return err
+		}
+		*gauge.metric = m
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package metrics
+
+import (
+	"context"
+	"strings"
+
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	"go.opentelemetry.io/otel/sdk/metric"
+	"google.golang.org/grpc/credentials"
+
+	"github.com/flashbots/node-healthchecker/config"
+)
+
+func newOTLPExporter(ctx context.Context, cfg *config.MetricsOTLP) (metric.Exporter, error) {
+	// the endpoint can be either the url (with scheme and, for http, path) or
+	// just host:port
+
+	hasScheme := strings.Contains(cfg.Endpoint, "://")
+
+	switch cfg.Protocol {
+	case config.OTLPProtocolHTTP:
+		opts := []otlpmetrichttp.Option{
+			otlpmetrichttp.WithHeaders(cfg.Headers),
+		}
+		if hasScheme {
+			opts = append(opts, otlpmetrichttp.WithEndpointURL(cfg.Endpoint))
+		} else {
+			opts = append(opts, otlpmetrichttp.WithEndpoint(cfg.Endpoint))
+		}
+		switch {
+		case cfg.Insecure:
+			opts = append(opts, otlpmetrichttp.WithInsecure())
+		case cfg.TLS != nil:
+			opts = append(opts, otlpmetrichttp.WithTLSClientConfig(cfg.TLS))
+		}
+		return otlpmetrichttp.New(ctx, opts...)
+
+	default:
+		opts := []otlpmetricgrpc.Option{
+			otlpmetricgrpc.WithHeaders(cfg.Headers),
+		}
+		if hasScheme {
+			opts = append(opts, otlpmetricgrpc.WithEndpointURL(cfg.Endpoint))
+		} else {
+			opts = append(opts, otlpmetricgrpc.WithEndpoint(cfg.Endpoint))
+		}
+		switch {
+		case cfg.Insecure:
+			opts = append(opts, otlpmetricgrpc.WithInsecure())
+		case cfg.TLS != nil:
+			opts = append(opts, otlpmetricgrpc.WithTLSCredentials(credentials.NewTLS(cfg.TLS)))
+		}
+		return otlpmetricgrpc.New(ctx, opts...)
+	}
+}
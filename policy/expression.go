@@ -0,0 +1,482 @@
+package policy
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+)
+
+var (
+	ErrInvalidExpression = errors.New("invalid expression")
+)
+
+// Source is what the expression is evaluated against for every healthcheck
+// source.
+type Source struct {
+	Status  string
+	Metrics map[string]float64
+}
+
+// Env maps the names of the healthcheck sources to their results.
+type Env map[string]*Source
+
+// Expression is a compiled boolean expression over the results of the
+// healthchecks, for example:
+//
+//	(ok("geth") || ok("reth")) && ok("lighthouse")
+//	status("op-node") == "error" || metric("op-node", "l1_distance") > 10
+//
+// Supported functions:
+//
+//   - ok(source), warning(source), error(source) return true if the source has
+//     the respective status.
+//   - status(source) returns the status of the source as a string ("ok",
+//     "warning", "error", "skipped" or "unknown").
+//   - metric(source, name) returns the value of the metric reported by the
+//     source (NaN if it's not available, which makes any comparison false).
+//   - count(status) returns the number of sources with the given status.
+type Expression struct {
+	src     string
+	root    node
+	sources []string
+}
+
+func Compile(src string) (*Expression, error) {
+	tokens, err := tokenize(src)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidExpression, err)
+	}
+
+	p := &parser{tokens: tokens}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidExpression, err)
+	}
+	if tok := p.peek(); tok.kind != tokenEOF {
+		return nil, fmt.Errorf("%w: unexpected '%s' at %d", ErrInvalidExpression, tok.text, tok.pos)
+	}
+	if root.typ() != typeBool {
+		return nil, fmt.Errorf("%w: expression must be boolean", ErrInvalidExpression)
+	}
+
+	slices.Sort(p.sources)
+
+	return &Expression{
+		src:     src,
+		root:    root,
+		sources: slices.Compact(p.sources),
+	}, nil
+}
+
+// Sources returns the names of the healthcheck sources that are referenced by
+// the expression.
+func (e *Expression) Sources() []string {
+	return e.sources
+}
+
+func (e *Expression) String() string {
+	return e.src
+}
+
+func (e *Expression) Eval(env Env) bool {
+	return e.root.eval(env).(bool)
+}
+
+// ast
+
+type valueType int
+
+const (
+	typeBool valueType = iota
+	typeNumber
+	typeString
+)
+
+func (t valueType) String() string {
+	switch t {
+	case typeBool:
+		return "boolean"
+	case typeNumber:
+		return "number"
+	default:
+		return "string"
+	}
+}
+
+type node interface {
+	eval(env Env) any
+	typ() valueType
+}
+
+type literal struct {
+	value any
+	t     valueType
+}
+
+func (n *literal) eval(_ Env) any {
+	return n.value
+}
+
+func (n *literal) typ() valueType {
+	return n.t
+}
+
+type not struct {
+	operand node
+}
+
+func (n *not) eval(env Env) any {
+	return !n.operand.eval(env).(bool)
+}
+
+func (n *not) typ() valueType {
+	return typeBool
+}
+
+type logical struct {
+	op          string
+	left, right node
+}
+
+func (n *logical) typ() valueType {
+	return typeBool
+}
+
+func (n *logical) eval(env Env) any {
+	left := n.left.eval(env).(bool)
+	switch n.op {
+	case "&&":
+		return left && n.right.eval(env).(bool)
+	default:
+		return left || n.right.eval(env).(bool)
+	}
+}
+
+type comparison struct {
+	op          string
+	left, right node
+}
+
+func (n *comparison) typ() valueType {
+	return typeBool
+}
+
+func (n *comparison) eval(env Env) any {
+	left, right := n.left.eval(env), n.right.eval(env)
+
+	if l, ok := left.(float64); ok && (math.IsNaN(l) || math.IsNaN(right.(float64))) {
+		return false // the metric is not available (so not even '!=' holds)
+	}
+
+	switch n.op {
+	case "==":
+		return left == right
+	case "!=":
+		return left != right
+	}
+
+	var cmp int
+	switch l := left.(type) {
+	case float64:
+		r := right.(float64)
+		switch {
+		case l < r:
+			cmp = -1
+		case l > r:
+			cmp = 1
+		}
+	case string:
+		r := right.(string)
+		switch {
+		case l < r:
+			cmp = -1
+		case l > r:
+			cmp = 1
+		}
+	}
+
+	switch n.op {
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	default:
+		return cmp >= 0
+	}
+}
+
+type call struct {
+	name string
+	args []node
+}
+
+func (n *call) typ() valueType {
+	return functions[n.name].result
+}
+
+func (n *call) eval(env Env) any {
+	args := make([]any, 0, len(n.args))
+	for _, arg := range n.args {
+		args = append(args, arg.eval(env))
+	}
+	return functions[n.name].impl(env, args)
+}
+
+// functions
+
+type function struct {
+	args   []valueType
+	result valueType
+	impl   func(env Env, args []any) any
+}
+
+func status(env Env, source string) string {
+	if s, ok := env[source]; ok && s.Status != "" {
+		return s.Status
+	}
+	return "unknown"
+}
+
+var functions = map[string]function{
+	"ok": {
+		args:   []valueType{typeString},
+		result: typeBool,
+		impl:   func(env Env, args []any) any { return status(env, args[0].(string)) == "ok" },
+	},
+
+	"warning": {
+		args:   []valueType{typeString},
+		result: typeBool,
+		impl:   func(env Env, args []any) any { return status(env, args[0].(string)) == "warning" },
+	},
+
+	"error": {
+		args:   []valueType{typeString},
+		result: typeBool,
+		impl:   func(env Env, args []any) any { return status(env, args[0].(string)) == "error" },
+	},
+
+	"status": {
+		args:   []valueType{typeString},
+		result: typeString,
+		impl:   func(env Env, args []any) any { return status(env, args[0].(string)) },
+	},
+
+	"metric": {
+		args:   []valueType{typeString, typeString},
+		result: typeNumber,
+		impl: func(env Env, args []any) any {
+			if s, ok := env[args[0].(string)]; ok {
+				if v, ok := s.Metrics[args[1].(string)]; ok {
+					return v
+				}
+			}
+			return math.NaN()
+		},
+	},
+
+	"count": {
+		args:   []valueType{typeString},
+		result: typeNumber,
+		impl: func(env Env, args []any) any {
+			count := 0
+			for source := range env {
+				if status(env, source) == args[0].(string) {
+					count++
+				}
+			}
+			return float64(count)
+		},
+	},
+}
+
+// parser
+
+type parser struct {
+	tokens  []token
+	pos     int
+	sources []string
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokenEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *parser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().kind == tokenOperator && p.peek().text == "||" {
+		tok := p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		if left.typ() != typeBool || right.typ() != typeBool {
+			return nil, fmt.Errorf("operands of '||' at %d must be boolean", tok.pos)
+		}
+		left = &logical{op: "||", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseComparison()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().kind == tokenOperator && p.peek().text == "&&" {
+		tok := p.next()
+		right, err := p.parseComparison()
+		if err != nil {
+			return nil, err
+		}
+		if left.typ() != typeBool || right.typ() != typeBool {
+			return nil, fmt.Errorf("operands of '&&' at %d must be boolean", tok.pos)
+		}
+		left = &logical{op: "&&", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseComparison() (node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	tok := p.peek()
+	if tok.kind != tokenOperator {
+		return left, nil
+	}
+	switch tok.text {
+	case "==", "!=", "<", "<=", ">", ">=":
+	default:
+		return left, nil
+	}
+	p.next()
+	right, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	if left.typ() != right.typ() {
+		return nil, fmt.Errorf("can not compare %s with %s at %d", left.typ(), right.typ(), tok.pos)
+	}
+	if left.typ() == typeBool && tok.text != "==" && tok.text != "!=" {
+		return nil, fmt.Errorf("booleans can not be ordered at %d", tok.pos)
+	}
+	return &comparison{op: tok.text, left: left, right: right}, nil
+}
+
+func (p *parser) parseUnary() (node, error) {
+	if tok := p.peek(); tok.kind == tokenOperator && tok.text == "!" {
+		p.next()
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		if operand.typ() != typeBool {
+			return nil, fmt.Errorf("operand of '!' at %d must be boolean", tok.pos)
+		}
+		return &not{operand: operand}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	tok := p.next()
+
+	switch tok.kind {
+	case tokenNumber:
+		return &literal{value: tok.num, t: typeNumber}, nil
+
+	case tokenString:
+		return &literal{value: tok.text, t: typeString}, nil
+
+	case tokenLParen:
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.next(); closing.kind != tokenRParen {
+			return nil, fmt.Errorf("expected ')' at %d", closing.pos)
+		}
+		return expr, nil
+
+	case tokenIdent:
+		switch tok.text {
+		case "true":
+			return &literal{value: true, t: typeBool}, nil
+		case "false":
+			return &literal{value: false, t: typeBool}, nil
+		}
+		return p.parseCall(tok)
+
+	case tokenEOF:
+		return nil, fmt.Errorf("unexpected end of expression")
+
+	default:
+		return nil, fmt.Errorf("unexpected '%s' at %d", tok.text, tok.pos)
+	}
+}
+
+func (p *parser) parseCall(name token) (node, error) {
+	fn, ok := functions[name.text]
+	if !ok {
+		return nil, fmt.Errorf("unknown function '%s' at %d", name.text, name.pos)
+	}
+	if open := p.next(); open.kind != tokenLParen {
+		return nil, fmt.Errorf("expected '(' after '%s' at %d", name.text, open.pos)
+	}
+
+	args := make([]node, 0, len(fn.args))
+	for p.peek().kind != tokenRParen {
+		if len(args) > 0 {
+			if comma := p.next(); comma.kind != tokenComma {
+				return nil, fmt.Errorf("expected ',' at %d", comma.pos)
+			}
+		}
+		arg, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+	}
+	p.next()
+
+	if len(args) != len(fn.args) {
+		return nil, fmt.Errorf("function '%s' at %d expects %d argument(s), got %d",
+			name.text, name.pos, len(fn.args), len(args),
+		)
+	}
+	for idx, arg := range args {
+		if arg.typ() != fn.args[idx] {
+			return nil, fmt.Errorf("argument %d of function '%s' at %d must be %s",
+				idx+1, name.text, name.pos, fn.args[idx],
+			)
+		}
+	}
+
+	if name.text != "count" {
+		source, ok := args[0].(*literal)
+		if !ok {
+			return nil, fmt.Errorf("source name of function '%s' at %d must be a string literal",
+				name.text, name.pos,
+			)
+		}
+		p.sources = append(p.sources, source.value.(string))
+	}
+
+	return &call{name: name.text, args: args}, nil
+}
@@ -0,0 +1,127 @@
+package policy
+
+import (
+	"errors"
+	"math"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestEval(t *testing.T) {
+	env := Env{
+		"geth": {
+			Status: "ok",
+			Metrics: map[string]float64{
+				"block_age_seconds": 12,
+				"peers":             math.NaN(),
+			},
+		},
+		"reth":       {Status: "error"},
+		"lighthouse": {Status: "warning"},
+	}
+
+	tests := []struct {
+		src      string
+		expected bool
+	}{
+		// functions
+		{`ok("geth")`, true},
+		{`warning("lighthouse")`, true},
+		{`error("reth")`, true},
+		{`ok("op-node")`, false},
+		{`status("op-node") == "unknown"`, true},
+		{`count("ok") == 1`, true},
+		{`metric("geth", "block_age_seconds") > 10`, true},
+		{`metric("geth", "block_age_seconds") <= 12`, true},
+
+		// precedence
+		{`ok("geth") || ok("reth") && ok("lighthouse")`, true},    // && binds tighter than ||
+		{`(ok("geth") || ok("reth")) && ok("lighthouse")`, false}, // unless grouped
+		{`!ok("reth") && ok("geth")`, true},                       // ! binds tighter than &&
+		{`!(ok("reth") || ok("geth"))`, false},
+		{`!ok("geth") == false`, true}, // ! binds tighter than ==
+		{`ok("geth") == true && count("error") > 0`, true},
+
+		// missing metrics make any comparison false
+		{`metric("geth", "missing") > 10`, false},
+		{`metric("geth", "missing") <= 10`, false},
+		{`metric("geth", "missing") == metric("geth", "missing")`, false},
+		{`metric("geth", "missing") != 10`, false},
+		{`metric("geth", "peers") >= 0`, false},
+		{`metric("op-node", "l1_distance") < 10`, false},
+		{`!(metric("geth", "missing") > 10)`, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.src, func(t *testing.T) {
+			expr, err := Compile(tt.src)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if actual := expr.Eval(env); actual != tt.expected {
+				t.Fatalf("expected %t, got %t", tt.expected, actual)
+			}
+		})
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	tests := []struct {
+		src   string
+		error string
+	}{
+		// type errors
+		{`status("geth")`, "expression must be boolean"},
+		{`ok("geth") && "ok"`, "operands of '&&' at 11 must be boolean"},
+		{`1 || ok("geth")`, "operands of '||' at 2 must be boolean"},
+		{`!status("geth")`, "operand of '!' at 0 must be boolean"},
+		{`status("geth") == 1`, "can not compare string with number at 15"},
+		{`ok("geth") < true`, "booleans can not be ordered at 11"},
+		{`ok(1)`, "argument 1 of function 'ok' at 0 must be string"},
+		{`metric("geth", 1) > 0`, "argument 2 of function 'metric' at 0 must be string"},
+		{`ok(status("geth"))`, "source name of function 'ok' at 0 must be a string literal"},
+
+		// arity
+		{`ok()`, "function 'ok' at 0 expects 1 argument(s), got 0"},
+		{`ok("geth", "reth")`, "function 'ok' at 0 expects 1 argument(s), got 2"},
+		{`metric("geth") > 0`, "function 'metric' at 0 expects 2 argument(s), got 1"},
+
+		// unknown functions
+		{`healthy("geth")`, "unknown function 'healthy' at 0"},
+		{`geth`, "unknown function 'geth' at 0"},
+
+		// syntax
+		{`ok("geth"`, "expected ',' at 9"},
+		{`(ok("geth")`, "expected ')' at 11"},
+		{`ok("geth") &&`, "unexpected end of expression"},
+		{`ok("geth") ok("reth")`, "unexpected 'ok' at 11"},
+		{`ok("geth) `, "unterminated string at 3"},
+		{`ok("geth") & ok("reth")`, "unexpected character '&' at 11"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.src, func(t *testing.T) {
+			_, err := Compile(tt.src)
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			if !errors.Is(err, ErrInvalidExpression) {
+				t.Errorf("expected invalid expression error, got %v", err)
+			}
+			if !strings.HasSuffix(err.Error(), tt.error) {
+				t.Errorf("expected error ending with '%s', got '%s'", tt.error, err)
+			}
+		})
+	}
+}
+
+func TestSources(t *testing.T) {
+	expr, err := Compile(`ok("reth") || metric("geth", "peers") > 0 && status("reth") != "error" || count("ok") > 1`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"geth", "reth"}; !slices.Equal(expr.Sources(), expected) {
+		t.Fatalf("expected sources %v, got %v", expected, expr.Sources())
+	}
+}
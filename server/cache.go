@@ -1,15 +1,105 @@
 package server
 
 import (
+	"context"
 	"sync"
 	"time"
+
+	"github.com/flashbots/node-healthchecker/healthcheck"
 )
 
+// cache holds the latest results of the healthchecks per source, so that each
+// source is cached for its own cool-off period.
 type cache struct {
-	expiry time.Time
+	entries map[string]*cacheEntry
+}
 
-	errs []error
-	wrns []error
+type cacheEntry struct {
+	expiry time.Time
+	result *healthcheck.Result
+	flight *flight
 
 	mx sync.Mutex
 }
+
+// flight is the healthcheck in progress that the concurrent requests wait for
+// instead of each of them probing the node on its own.
+type flight struct {
+	done   chan struct{}
+	result *healthcheck.Result
+}
+
+func newCache(sources []string) *cache {
+	c := &cache{
+		entries: make(map[string]*cacheEntry, len(sources)),
+	}
+	for _, source := range sources {
+		c.entries[source] = &cacheEntry{}
+	}
+	return c
+}
+
+// get returns the cached result (if it's still fresh), or joins the healthcheck
+// that is already in flight, or starts a new one.
+//
+// Within the stale-while-revalidate window after the expiry the stale result
+// is returned right away, while the refresh runs in background.
+func (e *cacheEntry) get(
+	ctx context.Context,
+	source string,
+	coolOff, stale time.Duration,
+	probe func() *healthcheck.Result,
+) (res *healthcheck.Result, cached bool) {
+	e.mx.Lock()
+
+	now := time.Now()
+	if e.result != nil && now.Before(e.expiry) {
+		defer e.mx.Unlock()
+		return e.result, true
+	}
+	if e.result != nil && now.Before(e.expiry.Add(stale)) {
+		defer e.mx.Unlock()
+		if e.flight == nil {
+			e.start(coolOff, probe)
+		}
+		return e.result, true
+	}
+
+	f := e.flight
+	if f == nil {
+		f = e.start(coolOff, probe)
+	}
+	e.mx.Unlock()
+
+	select {
+	case <-f.done:
+		return f.result, false
+	case <-ctx.Done():
+		return &healthcheck.Result{
+			Source: source,
+			Err:    ctx.Err(),
+		}, false
+	}
+}
+
+// start runs the probe in background (must be called with the lock held).
+func (e *cacheEntry) start(coolOff time.Duration, probe func() *healthcheck.Result) *flight {
+	f := &flight{
+		done: make(chan struct{}),
+	}
+	e.flight = f
+
+	go func() {
+		res := probe()
+
+		e.mx.Lock()
+		e.result, e.expiry = res, time.Now().Add(coolOff)
+		e.flight = nil
+		e.mx.Unlock()
+
+		f.result = res
+		close(f.done)
+	}()
+
+	return f
+}
@@ -0,0 +1,67 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/flashbots/node-healthchecker/consul"
+	"github.com/flashbots/node-healthchecker/healthcheck"
+	"github.com/flashbots/node-healthchecker/logutils"
+)
+
+// runConsul pushes the verdict (as reported at `/`) to the ttl check of the
+// consul service as soon as any source transitions, and also regularly enough
+// for the check not to expire.
+func (s *Server) runConsul(ctx context.Context, agent *consul.Agent, transitions <-chan *healthcheck.Transition) {
+	l := logutils.LoggerFromContext(ctx)
+
+	ticker := time.NewTicker(s.cfg.Consul.CheckTTL / 3)
+	defer ticker.Stop()
+
+	for {
+		status, output := s.consulStatus(ctx)
+		if err := agent.Update(ctx, status, output); err != nil && ctx.Err() == nil {
+			l.Error("Failed to update consul check",
+				zap.Error(err),
+			)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-transitions:
+		case <-ticker.C:
+		}
+	}
+}
+
+// consulStatus returns the verdict along with the details of the errors and
+// warnings (in the same format as at `/`).
+func (s *Server) consulStatus(ctx context.Context) (healthcheck.Status, string) {
+	if s.drain.get().Draining {
+		return healthcheck.StatusError, "draining"
+	}
+
+	// the check is refreshed more often than the results expire, so the
+	// cached ones are not worth a warning here
+	results, _ := s.check(ctx)
+	status, errs, wrns := s.verdict("/", nil, results)
+
+	lines := make([]string, 0, len(errs)+len(wrns))
+	for _, err := range errs {
+		lines = append(lines, fmt.Sprintf("%d: error: %s", len(lines), err))
+	}
+	for _, wrn := range wrns {
+		lines = append(lines, fmt.Sprintf("%d: warning: %s", len(lines), wrn))
+	}
+	for _, res := range results {
+		if res.Skipped {
+			lines = append(lines, fmt.Sprintf("%d: skipped: %s", len(lines), res.Error()))
+		}
+	}
+	return status, strings.Join(lines, "\n")
+}
@@ -0,0 +1,172 @@
+package server
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/flashbots/node-healthchecker/logutils"
+)
+
+// drainState is the maintenance mode in which the healthchecker reports the
+// node as draining regardless of the results of the healthchecks.
+type drainState struct {
+	Draining bool      `json:"draining"`
+	Since    time.Time `json:"since"`
+}
+
+type drain struct {
+	file  string
+	state drainState
+
+	mx sync.Mutex
+}
+
+// newDrain restores the drain state from the file (if it's configured and
+// exists).
+func newDrain(file string) (*drain, error) {
+	d := &drain{
+		file: file,
+	}
+	if file == "" {
+		return d, nil
+	}
+
+	data, err := os.ReadFile(file)
+	if errors.Is(err, os.ErrNotExist) {
+		return d, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &d.state); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+func (d *drain) get() drainState {
+	d.mx.Lock()
+	defer d.mx.Unlock()
+
+	return d.state
+}
+
+// set switches the drain mode and persists it (if the state file is
+// configured).
+func (d *drain) set(draining bool) (drainState, error) {
+	d.mx.Lock()
+	defer d.mx.Unlock()
+
+	return d.update(draining)
+}
+
+// toggle flips the drain mode.
+func (d *drain) toggle() (drainState, error) {
+	d.mx.Lock()
+	defer d.mx.Unlock()
+
+	return d.update(!d.state.Draining)
+}
+
+// update persists the new state first, and only then applies it (so that the
+// failure leaves the drain mode as it was).  Must be called under the lock.
+func (d *drain) update(draining bool) (drainState, error) {
+	state := d.state
+	if state.Draining != draining {
+		state = drainState{
+			Draining: draining,
+			Since:    time.Now(),
+		}
+	}
+
+	if err := d.persist(state); err != nil {
+		return d.state, err
+	}
+
+	d.state = state
+	return d.state, nil
+}
+
+func (d *drain) persist(state drainState) error {
+	if d.file == "" {
+		return nil
+	}
+
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(d.file), filepath.Base(d.file)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), d.file)
+}
+
+// handleDrain is the admin api to enable (POST), disable (DELETE) or inspect
+// (GET) the drain mode.
+func (s *Server) handleDrain(w http.ResponseWriter, r *http.Request) {
+	l := logutils.LoggerFromRequest(r)
+
+	// the endpoint is only registered with the token configured
+	expected := []byte("Bearer " + s.cfg.Drain.AdminToken)
+	if subtle.ConstantTimeCompare([]byte(r.Header.Get("authorization")), expected) != 1 {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var (
+		state drainState
+		err   error
+	)
+	switch r.Method {
+	case http.MethodGet:
+		state = s.drain.get()
+	case http.MethodPost:
+		state, err = s.drain.set(true)
+	case http.MethodDelete:
+		state, err = s.drain.set(false)
+	default:
+		w.Header().Set("Allow", "GET, POST, DELETE")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if err != nil {
+		l.Error("Failed to persist the drain state",
+			zap.Error(err),
+			zap.String("file", s.cfg.Drain.StateFile),
+		)
+		http.Error(w, "failed to persist the drain state", http.StatusInternalServerError)
+		return
+	}
+
+	if r.Method != http.MethodGet {
+		l.Info("Drain mode changed via admin api",
+			zap.Bool("draining", state.Draining),
+		)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(state); err != nil {
+		l.Error("Failed to write the response body",
+			zap.Error(err),
+		)
+	}
+}
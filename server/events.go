@@ -0,0 +1,242 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/flashbots/node-healthchecker/healthcheck"
+	"github.com/flashbots/node-healthchecker/logutils"
+)
+
+const (
+	eventsBufferSize = 64
+	eventsKeepAlive  = 15 * time.Second
+)
+
+// broker fans out the transitions to all of the subscribers.
+type broker struct {
+	subscribers map[chan *healthcheck.Transition]*follower // nil for the lossy ones
+
+	closed    chan struct{}
+	closeOnce sync.Once
+
+	mx sync.Mutex
+}
+
+// follower queues the transitions for the subscriber that must not miss any
+// (the consecutive ones of the same source are coalesced while it is busy, so
+// that the queue never outgrows the count of the sources).
+type follower struct {
+	ch      chan *healthcheck.Transition
+	pending []*healthcheck.Transition
+	wake    chan struct{}
+	done    chan struct{}
+
+	mx sync.Mutex
+}
+
+func newBroker() *broker {
+	return &broker{
+		subscribers: make(map[chan *healthcheck.Transition]*follower),
+		closed:      make(chan struct{}),
+	}
+}
+
+// close signals the streaming subscribers to end (as http server's shutdown
+// does not cancel the requests in flight).
+func (b *broker) close() {
+	b.closeOnce.Do(func() {
+		close(b.closed)
+	})
+}
+
+// subscribe returns the buffered channel of the transitions (that misses them
+// if the subscriber does not keep up).
+func (b *broker) subscribe() chan *healthcheck.Transition {
+	ch := make(chan *healthcheck.Transition, eventsBufferSize)
+
+	b.mx.Lock()
+	defer b.mx.Unlock()
+
+	b.subscribers[ch] = nil
+	return ch
+}
+
+// follow returns the channel of the transitions that delivers the latest
+// transition of every source no matter how slow the subscriber is.
+func (b *broker) follow() chan *healthcheck.Transition {
+	f := &follower{
+		ch:   make(chan *healthcheck.Transition),
+		wake: make(chan struct{}, 1),
+		done: make(chan struct{}),
+	}
+	go f.run()
+
+	b.mx.Lock()
+	defer b.mx.Unlock()
+
+	b.subscribers[f.ch] = f
+	return f.ch
+}
+
+func (b *broker) unsubscribe(ch chan *healthcheck.Transition) {
+	b.mx.Lock()
+	defer b.mx.Unlock()
+
+	if f := b.subscribers[ch]; f != nil {
+		close(f.done)
+	}
+	delete(b.subscribers, ch)
+}
+
+// publish delivers the transition to every follower, and to every other
+// subscriber that is keeping up (the slow ones miss it).
+func (b *broker) publish(transition *healthcheck.Transition) {
+	b.mx.Lock()
+	defer b.mx.Unlock()
+
+	for ch, f := range b.subscribers {
+		if f != nil {
+			f.push(transition)
+			continue
+		}
+		select {
+		case ch <- transition:
+		default:
+		}
+	}
+}
+
+// push queues the transition (merging it into the pending one of the same
+// source, if there is any).
+func (f *follower) push(transition *healthcheck.Transition) {
+	f.mx.Lock()
+	defer f.mx.Unlock()
+
+	if idx := slices.IndexFunc(f.pending, func(t *healthcheck.Transition) bool {
+		return t.Source == transition.Source
+	}); idx >= 0 {
+		merged := *transition
+		merged.From = f.pending[idx].From
+		if merged.From == merged.To { // the flip back cancels it out
+			f.pending = slices.Delete(f.pending, idx, idx+1)
+			return
+		}
+		f.pending[idx] = &merged
+		return
+	}
+
+	f.pending = append(f.pending, transition)
+	select {
+	case f.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (f *follower) pop() *healthcheck.Transition {
+	f.mx.Lock()
+	defer f.mx.Unlock()
+
+	if len(f.pending) == 0 {
+		return nil
+	}
+	transition := f.pending[0]
+	f.pending = f.pending[1:]
+	return transition
+}
+
+// run hands the queued transitions over to the subscriber until it
+// unsubscribes.
+func (f *follower) run() {
+	for {
+		transition := f.pop()
+		if transition == nil {
+			select {
+			case <-f.wake:
+				continue
+			case <-f.done:
+				return
+			}
+		}
+		select {
+		case f.ch <- transition:
+		case <-f.done:
+			return
+		}
+	}
+}
+
+// handleEvents streams the transitions as server-sent events.
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	l := logutils.LoggerFromRequest(r)
+
+	rc := http.NewResponseController(w)
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil {
+		l.Error("Failed to disable write deadline for the events stream",
+			zap.Error(err),
+		)
+		http.Error(w, "streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+
+	events := s.events.subscribe()
+	defer s.events.unsubscribe(events)
+
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.WriteHeader(http.StatusOK)
+
+	send := func(event string, data any) error {
+		payload, err := json.Marshal(data)
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, payload); err != nil {
+			return err
+		}
+		return rc.Flush()
+	}
+
+	if err := send("snapshot", s.state.snapshot()); err != nil {
+		l.Debug("Failed to send the snapshot event",
+			zap.Error(err),
+		)
+		return
+	}
+
+	keepAlive := time.NewTicker(eventsKeepAlive)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+
+		case <-s.events.closed:
+			return
+
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+			if err := rc.Flush(); err != nil {
+				return
+			}
+
+		case transition := <-events:
+			if err := send("transition", transition); err != nil {
+				l.Debug("Failed to send the transition event",
+					zap.Error(err),
+				)
+				return
+			}
+		}
+	}
+}
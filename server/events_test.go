@@ -0,0 +1,70 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/flashbots/node-healthchecker/healthcheck"
+)
+
+func TestBrokerFollow(t *testing.T) {
+	b := newBroker()
+
+	lossy := b.subscribe()
+	defer b.unsubscribe(lossy)
+	follower := b.follow()
+	defer b.unsubscribe(follower)
+
+	statuses := []healthcheck.Status{
+		healthcheck.StatusUnknown,
+		healthcheck.StatusOk,
+		healthcheck.StatusError,
+	}
+	for idx := range 2 * eventsBufferSize {
+		b.publish(&healthcheck.Transition{
+			Source: healthcheck.SourceGeth,
+			From:   statuses[idx%3],
+			To:     statuses[(idx+1)%3],
+		})
+	}
+	b.publish(&healthcheck.Transition{
+		Source: healthcheck.SourceLighthouse,
+		From:   healthcheck.StatusUnknown,
+		To:     healthcheck.StatusOk,
+	})
+
+	if len(lossy) != eventsBufferSize {
+		t.Errorf("expected the lossy subscriber to get %d transitions, got %d", eventsBufferSize, len(lossy))
+	}
+
+	// the follower might have picked up the first transition before the rest
+	// were coalesced, but either way it must end up with the latest status
+	geth := healthcheck.StatusUnknown
+	for count := 0; ; count++ {
+		if count > 3 {
+			t.Fatalf("expected the transitions to be coalesced, got %d", count)
+		}
+		var transition *healthcheck.Transition
+		select {
+		case transition = <-follower:
+		case <-time.After(time.Second):
+			t.Fatal("expected a transition")
+		}
+		if transition.Source == healthcheck.SourceLighthouse {
+			break
+		}
+		if transition.From != geth {
+			t.Fatalf("expected the transition from %s, got %+v", geth, transition)
+		}
+		geth = transition.To
+	}
+	if expected := statuses[2*eventsBufferSize%3]; geth != expected {
+		t.Fatalf("expected geth to end up %s, got %s", expected, geth)
+	}
+
+	select {
+	case transition := <-follower:
+		t.Fatalf("unexpected transition: %+v", transition)
+	case <-time.After(10 * time.Millisecond):
+	}
+}
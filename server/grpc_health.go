@@ -0,0 +1,142 @@
+package server
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+
+	"github.com/flashbots/node-healthchecker/healthcheck"
+)
+
+const (
+	grpcHealthDrainPollInterval  = time.Second
+	grpcHealthWatchProbeInterval = 5 * time.Second
+)
+
+// grpcHealth implements grpc health checking protocol, where the empty service
+// name stands for the overall verdict (as reported at `/`), and the names of
+// the sources (e.g. "geth") for their individual results.
+//
+// Warnings are reported as SERVING.
+//
+// See: https://github.com/grpc/grpc/blob/master/doc/health-checking.md
+type grpcHealth struct {
+	grpc_health_v1.UnimplementedHealthServer
+
+	ctx context.Context // is cancelled on shutdown
+	s   *Server
+}
+
+func (h *grpcHealth) Check(ctx context.Context, req *grpc_health_v1.HealthCheckRequest) (*grpc_health_v1.HealthCheckResponse, error) {
+	if !h.known(req.Service) {
+		return nil, status.Errorf(codes.NotFound, "unknown service: %s", req.Service)
+	}
+	return &grpc_health_v1.HealthCheckResponse{
+		Status: h.status(ctx, req.Service),
+	}, nil
+}
+
+func (h *grpcHealth) Watch(req *grpc_health_v1.HealthCheckRequest, stream grpc_health_v1.Health_WatchServer) error {
+	ctx := stream.Context()
+
+	if !h.known(req.Service) {
+		return stream.Send(&grpc_health_v1.HealthCheckResponse{
+			Status: grpc_health_v1.HealthCheckResponse_SERVICE_UNKNOWN,
+		})
+	}
+
+	transitions := h.s.events.follow()
+	defer h.s.events.unsubscribe(transitions)
+
+	ticker := time.NewTicker(grpcHealthDrainPollInterval)
+	defer ticker.Stop()
+
+	// without the background checks nothing would probe the node otherwise
+	var probe <-chan time.Time
+	if h.s.cfg.Healthcheck.Interval == 0 {
+		probeTicker := time.NewTicker(grpcHealthWatchProbeInterval)
+		defer probeTicker.Stop()
+		probe = probeTicker.C
+	}
+
+	draining := h.s.drain.get().Draining
+	current := h.status(ctx, req.Service)
+	if err := stream.Send(&grpc_health_v1.HealthCheckResponse{Status: current}); err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+
+		case <-h.ctx.Done():
+			return status.Error(codes.Unavailable, "shutting down")
+
+		case t := <-transitions:
+			if req.Service != "" && t.Source != req.Service {
+				continue
+			}
+
+		case <-probe:
+
+		case <-ticker.C:
+			if d := h.s.drain.get().Draining; d != draining {
+				draining = d
+			} else {
+				continue
+			}
+		}
+
+		if next := h.status(ctx, req.Service); next != current {
+			current = next
+			if err := stream.Send(&grpc_health_v1.HealthCheckResponse{Status: current}); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (h *grpcHealth) known(service string) bool {
+	if service == "" {
+		return true
+	}
+	return slices.ContainsFunc(h.s.monitors, func(m *monitor) bool {
+		return m.source == service
+	})
+}
+
+func (h *grpcHealth) status(ctx context.Context, service string) grpc_health_v1.HealthCheckResponse_ServingStatus {
+	if h.s.drain.get().Draining {
+		return grpc_health_v1.HealthCheckResponse_NOT_SERVING
+	}
+
+	results, _ := h.s.check(ctx)
+
+	if service == "" {
+		verdict, _, _ := h.s.verdict("/", nil, results)
+		return grpcServingStatus(verdict)
+	}
+
+	for _, res := range results {
+		if res.Source == service {
+			return grpcServingStatus(res.Status())
+		}
+	}
+	return grpc_health_v1.HealthCheckResponse_UNKNOWN
+}
+
+func grpcServingStatus(status healthcheck.Status) grpc_health_v1.HealthCheckResponse_ServingStatus {
+	switch status {
+	case healthcheck.StatusOk, healthcheck.StatusWarning:
+		return grpc_health_v1.HealthCheckResponse_SERVING
+	case healthcheck.StatusError:
+		return grpc_health_v1.HealthCheckResponse_NOT_SERVING
+	default:
+		return grpc_health_v1.HealthCheckResponse_UNKNOWN
+	}
+}
@@ -0,0 +1,104 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/flashbots/node-healthchecker/healthcheck"
+	"github.com/flashbots/node-healthchecker/logutils"
+)
+
+const (
+	haproxyAgentAcceptBackoffMin = 5 * time.Millisecond
+	haproxyAgentAcceptBackoffMax = time.Second
+	haproxyAgentTimeout          = 30 * time.Second
+)
+
+// runHAProxyAgent answers haproxy's agent-checks (one response per connection)
+// until the context is cancelled.
+//
+// See: https://docs.haproxy.org/2.9/configuration.html#5.2-agent-check
+func (s *Server) runHAProxyAgent(ctx context.Context, listener net.Listener) {
+	l := logutils.LoggerFromContext(ctx)
+
+	go func() {
+		<-ctx.Done()
+		listener.Close()
+	}()
+
+	var backoff time.Duration // same as net/http's server does
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			backoff = min(max(2*backoff, haproxyAgentAcceptBackoffMin), haproxyAgentAcceptBackoffMax)
+			l.Error("Failed to accept haproxy agent-check connection",
+				zap.Error(err),
+				zap.Duration("backoff", backoff),
+			)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(backoff):
+			}
+			continue
+		}
+		backoff = 0
+		go s.handleHAProxyAgent(ctx, conn)
+	}
+}
+
+func (s *Server) handleHAProxyAgent(ctx context.Context, conn net.Conn) {
+	defer conn.Close()
+
+	l := logutils.LoggerFromContext(ctx).With(
+		zap.String("remote_address", conn.RemoteAddr().String()),
+	)
+
+	if err := conn.SetDeadline(time.Now().Add(haproxyAgentTimeout)); err != nil {
+		l.Error("Failed to set haproxy agent-check connection deadline",
+			zap.Error(err),
+		)
+		return
+	}
+
+	response := s.haproxyAgentResponse(ctx)
+	if _, err := conn.Write([]byte(response + "\n")); err != nil {
+		l.Error("Failed to write haproxy agent-check response",
+			zap.Error(err),
+		)
+		return
+	}
+
+	l.Debug("Answered haproxy agent-check",
+		zap.String("response", response),
+	)
+}
+
+// haproxyAgentResponse maps the verdict (as reported at `/`) onto the
+// agent-check response.
+func (s *Server) haproxyAgentResponse(ctx context.Context) string {
+	if s.drain.get().Draining {
+		return "drain"
+	}
+
+	// haproxy checks more often than the results expire, so the cached ones
+	// are not worth lowering the weight
+	results, _ := s.check(ctx)
+	status, _, _ := s.verdict("/", nil, results)
+
+	switch status {
+	case healthcheck.StatusOk:
+		return "up " + s.cfg.HAProxyAgent.WeightOk
+	case healthcheck.StatusWarning:
+		return "up " + s.cfg.HAProxyAgent.WeightWarning
+	default:
+		return "down"
+	}
+}
@@ -5,160 +5,305 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
-
+	"slices"
 	"time"
 
 	"github.com/flashbots/node-healthchecker/healthcheck"
 	"github.com/flashbots/node-healthchecker/logutils"
-	"github.com/flashbots/node-healthchecker/metrics"
+	"github.com/flashbots/node-healthchecker/policy"
+	"github.com/flashbots/node-healthchecker/tracing"
 
 	"go.opentelemetry.io/otel/attribute"
-	otelapi "go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 )
 
 func (s *Server) healthcheck(w http.ResponseWriter, r *http.Request) {
-	if s.cfg.Healthcheck.CacheCoolOff != 0 {
-		s.cache.mx.Lock()
-		defer s.cache.mx.Unlock()
+	if s.drain.get().Draining {
+		s.reportDraining(w, r)
+		return
+	}
 
-		now := time.Now()
+	results, cached := s.check(r.Context())
 
-		if s.cache.expiry.After(now) {
-			s.report(w, r, true, s.cache.errs, s.cache.wrns)
-			return
-		}
+	s.report(w, r, cached, results)
+}
 
-		s.cache.expiry = now.Add(s.cfg.Healthcheck.CacheCoolOff)
+// check runs all monitors in parallel and returns their results (along with
+// the sources for which the results came from the cache).
+//
+// Concurrent checks of the same source are coalesced into one.
+func (s *Server) check(ctx context.Context) (results []*healthcheck.Result, cached []string) {
+	ctx, span := tracing.Start(ctx, "healthcheck")
+	defer func() {
+		span.SetAttributes(attribute.StringSlice("healthcheck.cached", cached))
+		span.End()
+	}()
+
+	type slot struct {
+		res    *healthcheck.Result
+		cached bool
+		done   chan struct{}
 	}
 
-	count := len(s.monitors)
-	results := make(chan *healthcheck.Result, count)
+	slots := make(map[string]*slot, len(s.monitors))
+	for _, m := range s.monitors {
+		slots[m.source] = &slot{done: make(chan struct{})}
+	}
 
 	for _, m := range s.monitors {
-		monitor := m // https://go.dev/blog/loopvar-preview
-		ctx, cancel := context.WithTimeout(r.Context(), s.cfg.Healthcheck.Timeout)
-		defer cancel()
 		go func() {
-			results <- monitor(ctx)
+			current := slots[m.source]
+			defer close(current.done)
+
+			current.res, current.cached = s.resolve(ctx, m)
 		}()
 	}
 
-	errs := []error{}
-	wrns := []error{}
-	for count > 0 {
-		count--
-		if res := <-results; res != nil {
-			if !res.Ok {
-				errs = append(errs, res.Error())
+	results = make([]*healthcheck.Result, 0, len(s.monitors))
+	cached = make([]string, 0)
+	for _, m := range s.monitors {
+		slot := slots[m.source]
+		<-slot.done
+		if slot.res == nil {
+			continue
+		}
+		if slot.cached {
+			cached = append(cached, m.source)
+		}
+		results = append(results, slot.res)
+	}
 
-				metrics.HealthcheckUp.Record(context.Background(), 0, otelapi.WithAttributes(
-					attribute.KeyValue{Key: "healthcheck_source", Value: attribute.StringValue(res.Source)},
-				))
+	s.recordOverall(results, cached)
 
-				metrics.HealthchecksNokCount.Add(context.Background(), 1, otelapi.WithAttributes(
-					attribute.KeyValue{Key: "healthcheck_source", Value: attribute.StringValue(res.Source)},
-				))
+	return results, cached
+}
 
-				if s.ok[res.Source] {
-					s.ok[res.Source] = false
-					metrics.HealthchecksFlipCount.Add(context.Background(), 1, otelapi.WithAttributes(
-						attribute.KeyValue{Key: "healthcheck_source", Value: attribute.StringValue(res.Source)},
-					))
+// resolve returns the cached result of the monitor (if it's still fresh), or
+// the one of the healthcheck in flight, or starts a new one.
+func (s *Server) resolve(ctx context.Context, m *monitor) (*healthcheck.Result, bool) {
+	return s.cache.entries[m.source].get(
+		ctx,
+		m.source,
+		m.coolOff,
+		s.cfg.Healthcheck.StaleWhileRevalidate,
+		func() *healthcheck.Result {
+			// the probe is shared by all concurrent requests, so neither it
+			// nor its dependencies must be cancelled along with the one that
+			// started it
+			ctx := context.WithoutCancel(ctx)
+			return s.probe(ctx, m, func(dep string) *healthcheck.Result {
+				idx := slices.IndexFunc(s.monitors, func(candidate *monitor) bool {
+					return candidate.source == dep
+				})
+				if idx < 0 {
+					return nil
 				}
+				res, _ := s.resolve(ctx, s.monitors[idx])
+				return res
+			})
+		},
+	)
+}
 
-				continue
+// probe runs the monitor (unless any of its dependencies is unhealthy) and
+// records the result.
+func (s *Server) probe(
+	ctx context.Context,
+	monitor *monitor,
+	dependency func(source string) *healthcheck.Result,
+) (res *healthcheck.Result) {
+	ctx, span := tracing.Start(ctx, "healthcheck "+monitor.source,
+		trace.WithAttributes(attribute.String("healthcheck.source", monitor.source)),
+	)
+	defer func() {
+		if res != nil {
+			span.SetAttributes(attribute.String("healthcheck.status", string(res.Status())))
+			if res.Err != nil {
+				span.RecordError(res.Err)
+			}
+			if !res.Ok && !res.Skipped {
+				span.SetStatus(codes.Error, res.Message())
 			}
+			s.record(res)
+		}
+		span.End()
+	}()
 
-			if res.Err != nil {
-				wrns = append(wrns, res.Error())
+	// the dependencies are guaranteed to be acyclic (see config), so waiting
+	// on them never deadlocks
+	for _, dep := range monitor.dependsOn {
+		if res := dependency(dep); res == nil || !res.Ok {
+			return &healthcheck.Result{
+				Source:  monitor.source,
+				Skipped: true,
+				Err: fmt.Errorf("dependency %s unhealthy",
+					dep,
+				),
 			}
+		}
+	}
 
-			metrics.HealthcheckUp.Record(context.Background(), 1, otelapi.WithAttributes(
-				attribute.KeyValue{Key: "healthcheck_source", Value: attribute.StringValue(res.Source)},
-			))
+	ctx, cancel := context.WithTimeout(ctx, monitor.timeout)
+	defer cancel()
 
-			metrics.HealthchecksOkCount.Add(context.Background(), 1, otelapi.WithAttributes(
-				attribute.KeyValue{Key: "healthcheck_source", Value: attribute.StringValue(res.Source)},
-			))
+	start := time.Now()
+	res = monitor.check(ctx)
+	if res != nil {
+		res.Latency = time.Since(start)
+	}
+	return res
+}
 
-			if !s.ok[res.Source] {
-				s.ok[res.Source] = true
-				metrics.HealthchecksFlipCount.Add(context.Background(), 1, otelapi.WithAttributes(
-					attribute.KeyValue{Key: "healthcheck_source", Value: attribute.StringValue(res.Source)},
-				))
-			}
+// verdict evaluates the results of the healthchecks according to the policy
+// of the endpoint at the path.
+//
+// Without policy, any error means error and any warning means warning (the
+// skipped healthchecks do not count, as their dependencies fail anyway).
+func (s *Server) verdict(path string, cached []string, results []*healthcheck.Result) (status healthcheck.Status, errs, wrns []error) {
+	errs = []error{}
+	wrns = []error{}
+	for _, res := range results {
+		switch res.Status() {
+		case healthcheck.StatusError:
+			errs = append(errs, res.Error())
+		case healthcheck.StatusWarning:
+			wrns = append(wrns, res.Error())
 		}
 	}
-	close(results)
+	for _, source := range cached {
+		wrns = append(wrns, fmt.Errorf("%s: cached healthcheck",
+			source,
+		))
+	}
 
-	s.report(w, r, false, errs, wrns)
+	rules, found := s.policies[path]
+	if !found {
+		rules = s.policies["/"]
+	}
+
+	if len(rules) == 0 {
+		switch {
+		case len(errs) > 0:
+			return healthcheck.StatusError, errs, wrns
+		case len(wrns) > 0:
+			return healthcheck.StatusWarning, errs, wrns
+		default:
+			return healthcheck.StatusOk, errs, wrns
+		}
+	}
 
-	if s.cfg.Healthcheck.CacheCoolOff != 0 {
-		s.cache.errs = errs
-		s.cache.wrns = append(wrns, errors.New("cached healthcheck"))
+	env := make(policy.Env, len(results))
+	for _, res := range results {
+		env[res.Source] = &policy.Source{
+			Status:  string(res.Status()),
+			Metrics: res.Metrics,
+		}
+	}
+	for _, rule := range rules {
+		if rule.Compiled.Eval(env) {
+			return healthcheck.Status(rule.Status), errs, wrns
+		}
 	}
+	return healthcheck.StatusOk, errs, wrns
 }
 
-func (s *Server) report(w http.ResponseWriter, r *http.Request, cached bool, errs, wrns []error) {
+// message joins the errors into multi-line text (empty if there are none).
+func message(errs []error) string {
+	if err := errors.Join(errs...); err != nil {
+		return err.Error()
+	}
+	return ""
+}
+
+func (s *Server) report(w http.ResponseWriter, r *http.Request, cached []string, results []*healthcheck.Result) {
 	l := logutils.LoggerFromRequest(r)
 
-	if cached {
-		l.Debug("Sending cached healthcheck")
+	if len(cached) > 0 {
+		l.Debug("Sending cached healthcheck",
+			zap.Strings("sources", cached),
+		)
 	}
 
-	switch {
-	case len(errs) == 0 && len(wrns) == 0:
-		w.WriteHeader(s.cfg.HttpStatus.Ok)
+	status, errs, wrns := s.verdict(r.URL.Path, cached, results)
+
+	httpStatus := s.cfg.HttpStatus.Ok
+	switch status {
+	case healthcheck.StatusError:
+		httpStatus = s.cfg.HttpStatus.Error
+	case healthcheck.StatusWarning:
+		httpStatus = s.cfg.HttpStatus.Warning
+	}
+
+	skips := []error{}
+	for _, res := range results {
+		if res.Skipped {
+			skips = append(skips, res.Error())
+		}
+	}
+
+	if len(errs) == 0 && len(wrns) == 0 && len(skips) == 0 {
+		w.WriteHeader(httpStatus)
 		return
+	}
 
-	case len(errs) > 0:
-		w.WriteHeader(s.cfg.HttpStatus.Error)
-		w.Header().Set("Content-Type", "application/text")
-
-		for idx, err := range errs {
-			line := fmt.Sprintf("%d: error: %s\n", idx, err)
-			if _, _err := w.Write([]byte(line)); _err != nil {
-				l.Error("Failed to write the response body",
-					zap.Error(_err),
-				)
-			}
+	w.Header().Set("Content-Type", "application/text")
+	w.WriteHeader(httpStatus)
+
+	for idx, err := range errs {
+		line := fmt.Sprintf("%d: error: %s\n", idx, err)
+		if _, _err := w.Write([]byte(line)); _err != nil {
+			l.Error("Failed to write the response body",
+				zap.Error(_err),
+			)
 		}
-		offset := len(errs)
-		for idx, warn := range wrns {
-			line := fmt.Sprintf("%d: warning: %s\n", offset+idx, warn)
-			if _, _err := w.Write([]byte(line)); _err != nil {
-				l.Error("Failed to write the response body",
-					zap.Error(_err),
-				)
-			}
+	}
+	offset := len(errs)
+	for idx, warn := range wrns {
+		line := fmt.Sprintf("%d: warning: %s\n", offset+idx, warn)
+		if _, _err := w.Write([]byte(line)); _err != nil {
+			l.Error("Failed to write the response body",
+				zap.Error(_err),
+			)
 		}
-
-		if !cached {
-			l.Warn("Healthcheck encountered upstream error(s)",
-				zap.Error(errors.Join(errs...)),
-				zap.Int("http_status", s.cfg.HttpStatus.Error),
+	}
+	offset += len(wrns)
+	for idx, skip := range skips {
+		line := fmt.Sprintf("%d: skipped: %s\n", offset+idx, skip)
+		if _, _err := w.Write([]byte(line)); _err != nil {
+			l.Error("Failed to write the response body",
+				zap.Error(_err),
 			)
 		}
+	}
 
-	case len(errs) == 0 && len(wrns) > 0:
-		w.WriteHeader(s.cfg.HttpStatus.Warning)
-		w.Header().Set("Content-Type", "application/text")
+	if len(cached) == len(results) {
+		return
+	}
 
-		for idx, warn := range wrns {
-			line := fmt.Sprintf("%d: warning: %s\n", idx, warn)
-			if _, _err := w.Write([]byte(line)); _err != nil {
-				l.Error("Failed to write the response body",
-					zap.Error(_err),
-				)
-			}
-		}
+	switch {
+	case len(errs) > 0:
+		l.Warn("Healthcheck encountered upstream error(s)",
+			zap.Error(errors.Join(errs...)),
+			zap.Int("http_status", httpStatus),
+		)
+	case len(wrns) > 0:
+		l.Warn("Healthcheck encountered upstream warning(s)",
+			zap.Error(errors.Join(wrns...)),
+			zap.Int("http_status", httpStatus),
+		)
+	}
+}
 
-		if !cached {
-			l.Warn("Healthcheck encountered upstream warning(s)",
-				zap.Error(errors.Join(wrns...)),
-				zap.Int("http_status", s.cfg.HttpStatus.Warning),
-			)
-		}
+func (s *Server) reportDraining(w http.ResponseWriter, r *http.Request) {
+	l := logutils.LoggerFromRequest(r)
+
+	w.Header().Set("Content-Type", "application/text")
+	w.WriteHeader(s.cfg.Drain.HttpStatus)
+	if _, err := w.Write([]byte("draining\n")); err != nil {
+		l.Error("Failed to write the response body",
+			zap.Error(err),
+		)
 	}
 }
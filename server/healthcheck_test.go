@@ -0,0 +1,90 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/flashbots/node-healthchecker/config"
+	"github.com/flashbots/node-healthchecker/healthcheck"
+)
+
+func TestCheckDetachesDependencies(t *testing.T) {
+	cfg := &config.Config{
+		HealthcheckGeth: config.HealthcheckGeth{
+			BaseURL:      "http://127.0.0.1:0", // the checks are replaced below
+			CacheCoolOff: time.Minute,
+			Timeout:      time.Second,
+		},
+		HealthcheckOpNode: config.HealthcheckOpNode{
+			BaseURL:      "http://127.0.0.1:0",
+			CacheCoolOff: time.Minute,
+			DependsOn:    config.StringList{healthcheck.SourceGeth},
+			Timeout:      time.Second,
+		},
+		History: config.History{
+			Size: 10,
+		},
+	}
+	s, err := New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gethStarted, gethRelease := make(chan struct{}), make(chan struct{})
+	opNodeDone := make(chan *healthcheck.Result, 1)
+	for _, m := range s.monitors {
+		switch m.source {
+		case healthcheck.SourceGeth:
+			m.check = func(context.Context) *healthcheck.Result {
+				close(gethStarted)
+				<-gethRelease
+				return &healthcheck.Result{Source: healthcheck.SourceGeth, Ok: true}
+			}
+		case healthcheck.SourceOpNode:
+			m.check = func(ctx context.Context) *healthcheck.Result {
+				res := &healthcheck.Result{Source: healthcheck.SourceOpNode, Ok: ctx.Err() == nil}
+				opNodeDone <- res
+				return res
+			}
+		}
+	}
+
+	// the request that started the probes goes away while geth is in flight
+	ctx, cancel := context.WithCancel(context.Background())
+	checked := make(chan []*healthcheck.Result)
+	go func() {
+		results, _ := s.check(ctx)
+		checked <- results
+	}()
+
+	<-gethStarted
+	cancel()
+	for _, res := range <-checked {
+		if !errors.Is(res.Err, context.Canceled) {
+			t.Errorf("expected the cancelled request to get the cancellation of %s, got %+v", res.Source, res)
+		}
+	}
+	close(gethRelease)
+
+	select {
+	case res := <-opNodeDone:
+		if !res.Ok {
+			t.Fatalf("expected op-node to be probed with live context, got %+v", res)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected op-node to be probed once geth is done (and not skipped)")
+	}
+
+	// the shared results are cached for the next request
+	results, cached := s.check(context.Background())
+	if len(cached) != 2 {
+		t.Fatalf("expected both results to come from the cache, got %v", cached)
+	}
+	for _, res := range results {
+		if res.Status() != healthcheck.StatusOk {
+			t.Errorf("expected %s to be ok, got %+v", res.Source, res)
+		}
+	}
+}
@@ -0,0 +1,279 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/flashbots/node-healthchecker/config"
+	"github.com/flashbots/node-healthchecker/healthcheck"
+	"github.com/flashbots/node-healthchecker/logutils"
+)
+
+const (
+	historyDefaultLimit = 100
+)
+
+// historyUptimeWindows are the sliding windows over which the uptime of the
+// sources is reported.
+var historyUptimeWindows = []struct {
+	name   string
+	length time.Duration
+}{
+	{"5m", 5 * time.Minute},
+	{"1h", time.Hour},
+	{"24h", 24 * time.Hour},
+}
+
+// historyResult is the outcome of a single healthcheck.
+type historyResult struct {
+	Timestamp time.Time          `json:"timestamp"`
+	Status    healthcheck.Status `json:"status"`
+	Message   string             `json:"message"`
+	Duration  time.Duration      `json:"duration"` // latency of the healthcheck
+}
+
+// historyTransition is a change of the status of a source.
+type historyTransition struct {
+	healthcheck.Transition
+
+	Duration time.Duration `json:"duration"` // how long the source was in the previous status
+}
+
+// ring is a fixed-capacity buffer that overwrites its oldest items.
+type ring[T any] struct {
+	items []T
+	next  int
+	full  bool
+}
+
+func newRing[T any](size int) *ring[T] {
+	return &ring[T]{
+		items: make([]T, size),
+	}
+}
+
+func (r *ring[T]) push(item T) {
+	r.items[r.next] = item
+	r.next = (r.next + 1) % len(r.items)
+	if r.next == 0 {
+		r.full = true
+	}
+}
+
+// latest returns up to limit of the most recent items, the latest first.
+func (r *ring[T]) latest(limit int) []T {
+	count := r.next
+	if r.full {
+		count = len(r.items)
+	}
+	count = min(count, limit)
+
+	res := make([]T, 0, count)
+	for idx := 1; idx <= count; idx++ {
+		res = append(res, r.items[(r.next-idx+len(r.items))%len(r.items)])
+	}
+	return res
+}
+
+// history keeps the recent results and transitions of every source.
+type history struct {
+	sources map[string]*sourceHistory
+
+	mx sync.Mutex
+}
+
+type sourceHistory struct {
+	results     *ring[historyResult]
+	transitions *ring[historyTransition]
+	timeline    *timeline
+}
+
+// newHistory keeps up to size of the recent results and transitions of every
+// source, and accounts their availability over the retention period.
+func newHistory(sources []string, size int, retention time.Duration) *history {
+	h := &history{
+		sources: make(map[string]*sourceHistory, len(sources)),
+	}
+	for _, source := range sources {
+		h.sources[source] = &sourceHistory{
+			results:     newRing[historyResult](size),
+			transitions: newRing[historyTransition](size),
+			timeline:    newTimeline(retention),
+		}
+	}
+	return h
+}
+
+// historyRetention returns the longest of the windows over which the
+// availability is reported (the uptime and the slo windows).
+func historyRetention(cfg *config.Config) time.Duration {
+	retention := historyUptimeWindows[len(historyUptimeWindows)-1].length
+	for _, objective := range cfg.SLO.Objectives {
+		retention = max(retention, objective.Duration)
+	}
+	for _, window := range cfg.SLO.BurnRateDurations {
+		retention = max(retention, window)
+	}
+	return retention
+}
+
+// record appends the result (and the transition it caused, if any) to the
+// history of the source.
+func (h *history) record(source string, result historyResult, transition *healthcheck.Transition, since time.Time) {
+	h.mx.Lock()
+	defer h.mx.Unlock()
+
+	sh, known := h.sources[source]
+	if !known {
+		return
+	}
+
+	if transition != nil {
+		result.Timestamp = transition.Timestamp
+		sh.transitions.push(historyTransition{
+			Transition: *transition,
+			Duration:   transition.Timestamp.Sub(since),
+		})
+		sh.timeline.set(transition.To, transition.Timestamp)
+	}
+	sh.results.push(result)
+}
+
+// restore appends the transition from the store to the history of its
+// source.
+func (h *history) restore(transition *historyTransition) {
+	h.mx.Lock()
+	defer h.mx.Unlock()
+
+	if sh, known := h.sources[transition.Source]; known {
+		sh.transitions.push(*transition)
+		sh.timeline.set(transition.To, transition.Timestamp)
+	}
+}
+
+// recentTransitions returns up to limit of the most recent transitions of all
+// sources, the latest first.
+func (h *history) recentTransitions(limit int) []healthcheck.Transition {
+	h.mx.Lock()
+	defer h.mx.Unlock()
+
+	res := make([]healthcheck.Transition, 0)
+	for _, sh := range h.sources {
+		for _, t := range sh.transitions.latest(limit) {
+			res = append(res, t.Transition)
+		}
+	}
+	slices.SortFunc(res, func(a, b healthcheck.Transition) int {
+		return b.Timestamp.Compare(a.Timestamp)
+	})
+	return res[:min(len(res), limit)]
+}
+
+// uptime returns the percentage of time (within each of the windows) that the
+// source spent in ok or warning status. The window is omitted if nothing was
+// observed within it at all.
+func (h *history) uptime(source string, now time.Time) map[string]float64 {
+	res := make(map[string]float64, len(historyUptimeWindows))
+	for _, window := range historyUptimeWindows {
+		if pct, _, observed := h.availability(source, now, window.length); observed {
+			res[window.name] = pct
+		}
+	}
+	return res
+}
+
+// availability returns the percentage of time (within the window) that the
+// source spent in ok or warning status, along with the percentage of the
+// window for which its status was known at all (the time before the first
+// known status and while the healthchecker was not running is not accounted
+// for). False is returned if nothing was observed within the window.
+func (h *history) availability(source string, now time.Time, window time.Duration) (pct, coverage float64, observed bool) {
+	h.mx.Lock()
+	defer h.mx.Unlock()
+
+	sh, known := h.sources[source]
+	if !known {
+		return 0, 0, false
+	}
+
+	up, total := sh.timeline.availability(now, window)
+	if total == 0 {
+		return 0, 0, false
+	}
+	return 100 * float64(up) / float64(total), min(100, 100*float64(total)/float64(window)), true
+}
+
+// historyReport is what `/history` responds with (for each source).
+type historyReport struct {
+	Source      string              `json:"source"`
+	Uptime      map[string]float64  `json:"uptime"`
+	SLO         []sloReport         `json:"slo,omitempty"`
+	Results     []historyResult     `json:"results"`
+	Transitions []historyTransition `json:"transitions"`
+}
+
+// handleHistory reports the recent results and transitions of the sources
+// (the latest first), along with their uptime.
+//
+// Query parameters:
+//
+//   - source: report only this source (all of them by default)
+//   - limit: maximum count of the results and transitions per source
+func (s *Server) handleHistory(w http.ResponseWriter, r *http.Request) {
+	l := logutils.LoggerFromRequest(r)
+
+	limit := historyDefaultLimit
+	if str := r.URL.Query().Get("limit"); str != "" {
+		num, err := strconv.Atoi(str)
+		if err != nil || num < 1 {
+			http.Error(w, "invalid limit: "+str, http.StatusBadRequest)
+			return
+		}
+		limit = num
+	}
+
+	sources := make([]string, 0, len(s.monitors))
+	if source := r.URL.Query().Get("source"); source != "" {
+		if _, known := s.history.sources[source]; !known {
+			http.Error(w, "unknown source: "+source, http.StatusNotFound)
+			return
+		}
+		sources = append(sources, source)
+	} else {
+		for _, m := range s.monitors {
+			sources = append(sources, m.source)
+		}
+		sources = append(sources, sourceOverall)
+	}
+
+	now := time.Now()
+	reports := make([]historyReport, 0, len(sources))
+	for _, source := range sources {
+		uptime := s.history.uptime(source, now)
+		slo := s.slo(source, now)
+
+		s.history.mx.Lock()
+		sh := s.history.sources[source]
+		reports = append(reports, historyReport{
+			Source:      source,
+			Uptime:      uptime,
+			SLO:         slo,
+			Results:     sh.results.latest(limit),
+			Transitions: sh.transitions.latest(limit),
+		})
+		s.history.mx.Unlock()
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(reports); err != nil {
+		l.Error("Failed to write the response body",
+			zap.Error(err),
+		)
+	}
+}
@@ -0,0 +1,116 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/flashbots/node-healthchecker/healthcheck"
+	"github.com/flashbots/node-healthchecker/kubernetes"
+	"github.com/flashbots/node-healthchecker/logutils"
+)
+
+const (
+	// kubernetesMessageRefreshResyncs is how many resync intervals the
+	// message of the pod condition is allowed to lag behind (as it changes
+	// with every block that the node falls behind by, for example).
+	kubernetesMessageRefreshResyncs = 10
+)
+
+// runKubernetes patches the pod condition whenever the verdict (as reported at
+// `/`) changes, and refreshes its message every once in a while. The verdict
+// is also re-evaluated periodically, to pick up the drain mode and to retry the
+// failed patches.
+func (s *Server) runKubernetes(ctx context.Context, patcher *kubernetes.Patcher, transitions <-chan *healthcheck.Transition) {
+	l := logutils.LoggerFromContext(ctx)
+
+	ticker := time.NewTicker(s.cfg.Kubernetes.ResyncInterval)
+	defer ticker.Stop()
+
+	var (
+		current *kubernetes.Condition
+		dirty   = true
+		patched time.Time
+	)
+
+	refresh := kubernetesMessageRefreshResyncs * s.cfg.Kubernetes.ResyncInterval
+
+	for {
+		next := s.kubernetesCondition(ctx)
+		switch {
+		case current == nil || current.Ready != next.Ready || current.Reason != next.Reason:
+			if current != nil && current.Ready == next.Ready {
+				next.Since = current.Since // transition time tracks the status only
+			}
+			current, dirty = next, true
+		case current.Message != next.Message && time.Since(patched) >= refresh:
+			next.Since = current.Since
+			current, dirty = next, true
+		}
+
+		if dirty {
+			if err := patcher.Patch(ctx, *current); err != nil {
+				if !errors.Is(err, context.Canceled) {
+					l.Error("Failed to patch kubernetes pod condition",
+						zap.Error(err),
+					)
+				}
+			} else {
+				dirty, patched = false, time.Now()
+				l.Info("Patched kubernetes pod condition",
+					zap.String("condition", s.cfg.Kubernetes.PodCondition),
+					zap.Bool("ready", current.Ready),
+					zap.String("reason", current.Reason),
+				)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-transitions:
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Server) kubernetesCondition(ctx context.Context) *kubernetes.Condition {
+	now := time.Now()
+
+	if s.drain.get().Draining {
+		return &kubernetes.Condition{
+			Ready:   false,
+			Reason:  "Draining",
+			Message: "the node is in drain mode",
+			Since:   now,
+		}
+	}
+
+	results, _ := s.check(ctx)
+	status, errs, wrns := s.verdict("/", nil, results)
+
+	switch status {
+	case healthcheck.StatusError:
+		return &kubernetes.Condition{
+			Ready:   false,
+			Reason:  "HealthcheckError",
+			Message: message(errs),
+			Since:   now,
+		}
+	case healthcheck.StatusWarning:
+		return &kubernetes.Condition{
+			Ready:   true,
+			Reason:  "HealthcheckWarning",
+			Message: message(wrns),
+			Since:   now,
+		}
+	default:
+		return &kubernetes.Condition{
+			Ready:  true,
+			Reason: "HealthcheckOk",
+			Since:  now,
+		}
+	}
+}
@@ -0,0 +1,186 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/flashbots/node-healthchecker/config"
+	"github.com/flashbots/node-healthchecker/healthcheck"
+	"github.com/flashbots/node-healthchecker/metrics"
+)
+
+func TestMain(m *testing.M) {
+	if err := metrics.Setup(context.Background(), &config.Metrics{}); err != nil {
+		panic(err)
+	}
+	os.Exit(m.Run())
+}
+
+type fakePodCondition struct {
+	Status  string `json:"status"`
+	Reason  string `json:"reason"`
+	Message string `json:"message"`
+}
+
+type fakePatch struct {
+	condition fakePodCondition
+	received  time.Time
+}
+
+func TestRunKubernetes(t *testing.T) {
+	var (
+		patches []fakePatch
+		mx      sync.Mutex
+	)
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var patch struct {
+			Status struct {
+				Conditions []fakePodCondition `json:"conditions"`
+			} `json:"status"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&patch); err != nil || len(patch.Status.Conditions) != 1 {
+			http.Error(w, "bad patch", http.StatusBadRequest)
+			return
+		}
+		mx.Lock()
+		patches = append(patches, fakePatch{
+			condition: patch.Status.Conditions[0],
+			received:  time.Now(),
+		})
+		mx.Unlock()
+		_, _ = w.Write([]byte("{}"))
+	}))
+	defer api.Close()
+
+	patched := func() []fakePatch {
+		mx.Lock()
+		defer mx.Unlock()
+		return append([]fakePatch{}, patches...)
+	}
+
+	cfg := &config.Config{
+		HealthcheckGeth: config.HealthcheckGeth{
+			BaseURL: "http://127.0.0.1:0", // the check is replaced below
+			Timeout: time.Second,
+		},
+		History: config.History{
+			Size: 10,
+		},
+		Kubernetes: config.Kubernetes{
+			APIServer:      api.URL,
+			Namespace:      "default",
+			PodCondition:   "example.com/node-healthy",
+			PodName:        "node-0",
+			ResyncInterval: 10 * time.Millisecond,
+			Timeout:        time.Second,
+		},
+	}
+	s, err := New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		result   = func() *healthcheck.Result { return &healthcheck.Result{Source: healthcheck.SourceGeth, Ok: true} }
+		resultMx sync.Mutex
+	)
+	setResult := func(fn func() *healthcheck.Result) {
+		resultMx.Lock()
+		defer resultMx.Unlock()
+		result = fn
+	}
+	s.monitors[0].check = func(context.Context) *healthcheck.Result {
+		resultMx.Lock()
+		defer resultMx.Unlock()
+		return result()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go s.runKubernetes(ctx, s.kubernetes, make(chan *healthcheck.Transition))
+
+	// await waits for the count of patches (the extra ones would show up
+	// as the mismatch at the next step)
+	await := func(step string, count int) []fakePatch {
+		t.Helper()
+		deadline := time.Now().Add(5 * time.Second)
+		for {
+			if actual := patched(); len(actual) >= count {
+				return actual
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("%s: expected %d patches, got %+v", step, count, patched())
+			}
+			time.Sleep(cfg.Kubernetes.ResyncInterval)
+		}
+	}
+	expect := func(step string, expected ...fakePodCondition) {
+		t.Helper()
+		actual := await(step, len(expected))
+		if len(actual) != len(expected) {
+			t.Fatalf("%s: expected %d patches, got %+v", step, len(expected), actual)
+		}
+		for idx := range expected {
+			if actual[idx].condition != expected[idx] {
+				t.Fatalf("%s: patch %d: expected %+v, got %+v", step, idx, expected[idx], actual[idx].condition)
+			}
+		}
+	}
+
+	ok := fakePodCondition{Status: "True", Reason: "HealthcheckOk"}
+	expect("initial status is patched",
+		ok,
+	)
+
+	setResult(func() *healthcheck.Result {
+		return &healthcheck.Result{Source: healthcheck.SourceGeth, Ok: true, Err: errors.New("block is 20s old")}
+	})
+	warning := fakePodCondition{Status: "True", Reason: "HealthcheckWarning", Message: "geth: block is 20s old"}
+	expect("new reason is patched (and unchanged status is not re-patched)",
+		ok, warning,
+	)
+
+	// the message changes with every probe, but is refreshed only once in a
+	// while
+	age := 20
+	setResult(func() *healthcheck.Result {
+		age++
+		return &healthcheck.Result{Source: healthcheck.SourceGeth, Ok: true, Err: fmt.Errorf("block is %ds old", age)}
+	})
+	refresh := kubernetesMessageRefreshResyncs * cfg.Kubernetes.ResyncInterval
+	refreshed := await("new message is refreshed", 5)
+	for idx := 1; idx < len(refreshed); idx++ {
+		if refreshed[idx].condition.Reason != "HealthcheckWarning" {
+			t.Fatalf("new message is refreshed: unexpected patch %d: %+v", idx, refreshed[idx].condition)
+		}
+		if gap := refreshed[idx].received.Sub(refreshed[idx-1].received); idx > 1 && gap < refresh {
+			t.Fatalf("new message is refreshed: patch %d came %s after the previous one (expected at least %s)", idx, gap, refresh)
+		}
+	}
+	if refreshed[2].condition.Message == warning.Message {
+		t.Fatalf("new message is refreshed: expected new message, got %+v", refreshed[2].condition)
+	}
+
+	setResult(func() *healthcheck.Result {
+		return &healthcheck.Result{Source: healthcheck.SourceGeth, Ok: false, Err: errors.New("still syncing")}
+	})
+	syncing := fakePodCondition{Status: "False", Reason: "HealthcheckError", Message: "geth: still syncing"}
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		actual := patched()
+		if last := actual[len(actual)-1].condition; last == syncing {
+			break
+		} else if last.Reason != "HealthcheckWarning" || time.Now().After(deadline) {
+			t.Fatalf("new status is patched: expected %+v, got %+v", syncing, last)
+		}
+		time.Sleep(cfg.Kubernetes.ResyncInterval)
+	}
+}
@@ -0,0 +1,19 @@
+package server
+
+import (
+	"time"
+
+	"github.com/flashbots/node-healthchecker/healthcheck"
+)
+
+// monitor is a healthcheck of a source that is skipped when any of the sources
+// it depends on is unhealthy (so that the root cause is not buried under the
+// cascading errors).
+type monitor struct {
+	source    string
+	dependsOn []string
+	check     healthcheck.Monitor
+
+	coolOff time.Duration
+	timeout time.Duration
+}
@@ -3,20 +3,31 @@ package server
 import (
 	"context"
 	"errors"
+	"fmt"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"slices"
 	"syscall"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health/grpc_health_v1"
 
 	"github.com/flashbots/node-healthchecker/config"
+	"github.com/flashbots/node-healthchecker/consul"
 	"github.com/flashbots/node-healthchecker/healthcheck"
 	"github.com/flashbots/node-healthchecker/httplogger"
+	"github.com/flashbots/node-healthchecker/kubernetes"
 	"github.com/flashbots/node-healthchecker/logutils"
 	"github.com/flashbots/node-healthchecker/metrics"
+	"github.com/flashbots/node-healthchecker/store"
+	"github.com/flashbots/node-healthchecker/systemd"
+	"github.com/flashbots/node-healthchecker/tracing"
+	"github.com/flashbots/node-healthchecker/webhook"
 )
 
 type Server struct {
@@ -26,60 +37,153 @@ type Server struct {
 
 	logger *zap.Logger
 	server *http.Server
+	grpc   *grpc.Server
 
 	cache    *cache
-	monitors []healthcheck.Monitor
+	heads    []*healthcheck.Heads
+	monitors []*monitor
+	watchdog time.Duration // systemd watchdog interval
 
-	ok map[string]bool
+	drain    *drain
+	events   *broker
+	history  *history
+	store    *store.Store
+	policies map[string][]*config.PolicyRule
+	state    *state
+
+	consul     *consul.Agent
+	kubernetes *kubernetes.Patcher
+	notifier   *webhook.Notifier
 }
 
 func New(cfg *config.Config) (*Server, error) {
-	monitors := make([]healthcheck.Monitor, 0)
-	ok := make(map[string]bool, len(monitors))
+	heads := make([]*healthcheck.Heads, 0)
+	monitors := make([]*monitor, 0)
 
 	if cfg.HealthcheckGeth.BaseURL != "" {
-		ok[healthcheck.SourceGeth] = true
-		monitors = append(monitors, func(ctx context.Context) *healthcheck.Result {
-			return healthcheck.Geth(ctx, &cfg.HealthcheckGeth)
+		var gethHeads *healthcheck.Heads
+		if cfg.HealthcheckGeth.WebsocketURL != "" {
+			gethHeads = healthcheck.NewHeads(cfg.HealthcheckGeth.WebsocketURL)
+			heads = append(heads, gethHeads)
+		}
+		monitors = append(monitors, &monitor{
+			source:    healthcheck.SourceGeth,
+			dependsOn: cfg.HealthcheckGeth.DependsOn,
+			coolOff:   cfg.HealthcheckGeth.CacheCoolOff,
+			timeout:   cfg.HealthcheckGeth.Timeout,
+			check: func(ctx context.Context) *healthcheck.Result {
+				return healthcheck.Geth(ctx, &cfg.HealthcheckGeth, gethHeads)
+			},
 		})
 	}
 
 	if cfg.HealthcheckLighthouse.BaseURL != "" {
-		ok[healthcheck.SourceLighthouse] = true
-		monitors = append(monitors, func(ctx context.Context) *healthcheck.Result {
-			return healthcheck.Lighthouse(ctx, &cfg.HealthcheckLighthouse)
+		monitors = append(monitors, &monitor{
+			source:    healthcheck.SourceLighthouse,
+			dependsOn: cfg.HealthcheckLighthouse.DependsOn,
+			coolOff:   cfg.HealthcheckLighthouse.CacheCoolOff,
+			timeout:   cfg.HealthcheckLighthouse.Timeout,
+			check: func(ctx context.Context) *healthcheck.Result {
+				return healthcheck.Lighthouse(ctx, &cfg.HealthcheckLighthouse)
+			},
 		})
 	}
 
 	if cfg.HealthcheckOpNode.BaseURL != "" {
-		ok[healthcheck.SourceOpNode] = true
-		monitors = append(monitors, func(ctx context.Context) *healthcheck.Result {
-			return healthcheck.OpNode(ctx, &cfg.HealthcheckOpNode)
+		monitors = append(monitors, &monitor{
+			source:    healthcheck.SourceOpNode,
+			dependsOn: cfg.HealthcheckOpNode.DependsOn,
+			coolOff:   cfg.HealthcheckOpNode.CacheCoolOff,
+			timeout:   cfg.HealthcheckOpNode.Timeout,
+			check: func(ctx context.Context) *healthcheck.Result {
+				return healthcheck.OpNode(ctx, &cfg.HealthcheckOpNode)
+			},
 		})
 	}
 
 	if cfg.HealthcheckReth.BaseURL != "" {
-		ok[healthcheck.SourceReth] = true
-		monitors = append(monitors, func(ctx context.Context) *healthcheck.Result {
-			return healthcheck.Reth(ctx, &cfg.HealthcheckReth)
+		var rethHeads *healthcheck.Heads
+		if cfg.HealthcheckReth.WebsocketURL != "" {
+			rethHeads = healthcheck.NewHeads(cfg.HealthcheckReth.WebsocketURL)
+			heads = append(heads, rethHeads)
+		}
+		monitors = append(monitors, &monitor{
+			source:    healthcheck.SourceReth,
+			dependsOn: cfg.HealthcheckReth.DependsOn,
+			coolOff:   cfg.HealthcheckReth.CacheCoolOff,
+			timeout:   cfg.HealthcheckReth.Timeout,
+			check: func(ctx context.Context) *healthcheck.Result {
+				return healthcheck.Reth(ctx, &cfg.HealthcheckReth, rethHeads)
+			},
 		})
 	}
 
+	drain, err := newDrain(cfg.Drain.StateFile)
+	if err != nil {
+		return nil, err
+	}
+
+	sources := make([]string, 0, len(monitors))
+	for _, m := range monitors {
+		sources = append(sources, m.source)
+	}
+
+	policies := make(map[string][]*config.PolicyRule)
+	for _, rule := range cfg.Policy {
+		policies[rule.Path] = append(policies[rule.Path], rule)
+	}
+
 	s := &Server{
+		cache:    newCache(sources),
 		cfg:      cfg,
+		drain:    drain,
 		failure:  make(chan error, 1),
+		heads:    heads,
 		logger:   zap.L(),
 		monitors: monitors,
-		ok:       ok,
+		events:   newBroker(),
+		history:  newHistory(append(slices.Clone(sources), sourceOverall), cfg.History.Size, historyRetention(cfg)),
+		policies: policies,
+		state:    newState(sources),
+	}
+
+	if cfg.History.Store.File != "" {
+		st, err := store.Open(&cfg.History.Store)
+		if err != nil {
+			return nil, err
+		}
+		s.store = st
+	}
+
+	if cfg.Consul.Address != "" {
+		s.consul = consul.New(&cfg.Consul)
 	}
 
-	if cfg.Healthcheck.CacheCoolOff != 0 {
-		s.cache = &cache{}
+	if cfg.Kubernetes.PodCondition != "" {
+		s.kubernetes = kubernetes.New(&cfg.Kubernetes)
+	}
+
+	if len(cfg.Webhook.Endpoints) > 0 {
+		notifier, err := webhook.New(&cfg.Webhook)
+		if err != nil {
+			return nil, err
+		}
+		s.notifier = notifier
 	}
 
 	mux := http.NewServeMux()
 	mux.HandleFunc("/", s.healthcheck)
-	mux.Handle("/metrics", promhttp.Handler())
+	mux.HandleFunc("/events", s.handleEvents)
+	mux.HandleFunc("/history", s.handleHistory)
+	mux.HandleFunc("/status", s.handleStatus)
+	if cfg.Drain.AdminToken != "" {
+		mux.HandleFunc("/admin/drain", s.handleDrain)
+	} else { // rather than a healthcheck that would look like a success
+		mux.HandleFunc("/admin/drain", http.NotFound)
+	}
+	if cfg.Metrics.Prometheus {
+		mux.Handle("/metrics", promhttp.Handler())
+	}
 	handler := httplogger.Middleware(s.logger, mux)
 
 	s.server = &http.Server{
@@ -91,6 +195,7 @@ func New(cfg *config.Config) (*Server, error) {
 		ReadTimeout:       30 * time.Second,
 		WriteTimeout:      30 * time.Second,
 	}
+	s.server.RegisterOnShutdown(s.events.close)
 
 	return s, nil
 }
@@ -99,7 +204,173 @@ func (s *Server) Run() error {
 	l := s.logger
 	ctx := logutils.ContextWithLogger(context.Background(), l)
 
-	if err := metrics.Setup(ctx); err != nil {
+	if err := metrics.Setup(ctx, &s.cfg.Metrics); err != nil {
+		return err
+	}
+
+	if err := tracing.Setup(ctx, &s.cfg.Tracing, s.cfg.Metrics.ResourceAttributes); err != nil {
+		return err
+	}
+
+	watchdog, err := systemd.WatchdogInterval()
+	if err != nil {
+		return err
+	}
+	if watchdog > 0 && (s.cfg.Healthcheck.Interval == 0 || s.cfg.Healthcheck.Interval >= watchdog) {
+		return fmt.Errorf("systemd watchdog requires --healthcheck-interval to be shorter than WatchdogSec (%s)",
+			watchdog,
+		)
+	}
+	s.watchdog = watchdog
+
+	if s.store != nil {
+		defer func() {
+			if err := s.storeSamples(); err != nil {
+				l.Error("Failed to store the samples",
+					zap.Error(err),
+				)
+			}
+			if err := s.store.Close(); err != nil {
+				l.Error("Failed to close the history store",
+					zap.Error(err),
+				)
+			}
+		}()
+		if err := s.restoreHistory(ctx); err != nil {
+			return err
+		}
+	}
+
+	background, stopBackground := context.WithCancel(ctx)
+	defer stopBackground()
+
+	for _, h := range s.heads {
+		go h.Run(background)
+	}
+
+	if s.notifier != nil {
+		transitions := s.events.follow()
+		defer s.events.unsubscribe(transitions)
+		go s.notifier.Run(background, transitions)
+	}
+
+	if systemd.Enabled() && s.cfg.Healthcheck.Interval == 0 { // otherwise background checks keep systemd up to date
+		transitions := s.events.follow()
+		defer s.events.unsubscribe(transitions)
+		go func() {
+			for {
+				select {
+				case <-background.Done():
+					return
+				case <-transitions:
+					s.notifySystemd(background, false)
+				}
+			}
+		}()
+	}
+
+	if s.consul != nil {
+		if err := s.consul.Register(ctx); err != nil {
+			return err
+		}
+		l.Info("Registered consul service",
+			zap.String("consul_service_id", s.cfg.Consul.ServiceID),
+		)
+		defer func() {
+			ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+			defer cancel()
+			if err := s.consul.Deregister(ctx); err != nil {
+				l.Error("Failed to deregister consul service",
+					zap.Error(err),
+				)
+				return
+			}
+			l.Info("Deregistered consul service",
+				zap.String("consul_service_id", s.cfg.Consul.ServiceID),
+			)
+		}()
+
+		transitions := s.events.follow()
+		defer s.events.unsubscribe(transitions)
+		go s.runConsul(background, s.consul, transitions)
+	}
+
+	if s.kubernetes != nil {
+		transitions := s.events.follow()
+		defer s.events.unsubscribe(transitions)
+		go s.runKubernetes(background, s.kubernetes, transitions)
+	}
+
+	if s.store != nil {
+		go s.runStore(background)
+	}
+
+	if len(s.cfg.SLO.Objectives) > 0 {
+		go s.runSLO(background)
+	}
+
+	if s.cfg.Healthcheck.Interval != 0 {
+		go s.runBackgroundChecks(background)
+	}
+
+	if s.cfg.GRPC.ListenAddress != "" {
+		listener, err := net.Listen("tcp", s.cfg.GRPC.ListenAddress)
+		if err != nil {
+			return err
+		}
+		s.grpc = grpc.NewServer()
+		grpc_health_v1.RegisterHealthServer(s.grpc, &grpcHealth{
+			ctx: background,
+			s:   s,
+		})
+		go func() {
+			l.Info("gRPC health server is going up...",
+				zap.String("grpc_listen_address", s.cfg.GRPC.ListenAddress),
+			)
+			if err := s.grpc.Serve(listener); err != nil {
+				s.failure <- err
+			}
+			l.Info("gRPC health server is down")
+		}()
+	}
+
+	if s.cfg.HAProxyAgent.ListenAddress != "" {
+		listener, err := net.Listen("tcp", s.cfg.HAProxyAgent.ListenAddress)
+		if err != nil {
+			return err
+		}
+		l.Info("HAProxy agent-check listener is going up...",
+			zap.String("haproxy_agent_listen_address", s.cfg.HAProxyAgent.ListenAddress),
+		)
+		go s.runHAProxyAgent(background, listener)
+	}
+
+	go func() { // toggle drain mode on SIGUSR1
+		toggler := make(chan os.Signal, 1)
+		signal.Notify(toggler, syscall.SIGUSR1)
+		defer signal.Stop(toggler)
+
+		for {
+			select {
+			case <-background.Done():
+				return
+			case <-toggler:
+				state, err := s.drain.toggle()
+				if err != nil {
+					l.Error("Failed to persist the drain state",
+						zap.Error(err),
+						zap.String("file", s.cfg.Drain.StateFile),
+					)
+				}
+				l.Info("Drain mode toggled via signal",
+					zap.Bool("draining", state.Draining),
+				)
+			}
+		}
+	}()
+
+	listener, err := net.Listen("tcp", s.cfg.Server.ListenAddress)
+	if err != nil {
 		return err
 	}
 
@@ -107,12 +378,18 @@ func (s *Server) Run() error {
 		l.Info("Blockchain node healthchecker server is going up...",
 			zap.String("server_listen_address", s.cfg.Server.ListenAddress),
 		)
-		if err := s.server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		if err := s.server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
 			s.failure <- err
 		}
 		l.Info("Blockchain node healthchecker server is down")
 	}()
 
+	if err := systemd.Notify(systemd.Ready, systemd.Status(s.systemdStatus())); err != nil {
+		l.Error("Failed to notify systemd",
+			zap.Error(err),
+		)
+	}
+
 	errs := []error{}
 	{ // wait until termination or internal failure
 		terminator := make(chan os.Signal, 1)
@@ -143,6 +420,18 @@ func (s *Server) Run() error {
 		}
 	}
 
+	if err := systemd.Notify(systemd.Stopping, systemd.Status("shutting down")); err != nil {
+		l.Error("Failed to notify systemd",
+			zap.Error(err),
+		)
+	}
+
+	stopBackground() // also ends the grpc watch streams
+
+	if s.grpc != nil { // stop the grpc server
+		s.grpc.GracefulStop()
+	}
+
 	{ // stop the server
 		ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
 		defer cancel()
@@ -153,6 +442,26 @@ func (s *Server) Run() error {
 		}
 	}
 
+	{ // flush the metrics
+		ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+		if err := metrics.Shutdown(ctx); err != nil {
+			l.Error("Failed to flush the metrics",
+				zap.Error(err),
+			)
+		}
+	}
+
+	{ // flush the traces
+		ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+		if err := tracing.Shutdown(ctx); err != nil {
+			l.Error("Failed to flush the traces",
+				zap.Error(err),
+			)
+		}
+	}
+
 	switch len(errs) {
 	default:
 		return errors.Join(errs...)
@@ -162,3 +471,26 @@ func (s *Server) Run() error {
 		return nil
 	}
 }
+
+// runBackgroundChecks periodically runs the healthchecks so that the state of
+// the sources is kept up to date even without incoming requests.
+//
+// Every completed round also pings systemd watchdog, so that a wedged
+// healthchecker gets restarted.
+func (s *Server) runBackgroundChecks(ctx context.Context) {
+	ticker := time.NewTicker(s.cfg.Healthcheck.Interval)
+	defer ticker.Stop()
+
+	for {
+		s.check(ctx)
+		if ctx.Err() == nil {
+			s.notifySystemd(ctx, true)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
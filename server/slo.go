@@ -0,0 +1,117 @@
+package server
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	otelapi "go.opentelemetry.io/otel/metric"
+
+	"github.com/flashbots/node-healthchecker/metrics"
+)
+
+const (
+	sloEvaluationInterval = 15 * time.Second
+)
+
+// sloReport is the state of a source against a service-level objective.
+//
+// The availability and the remaining error budget are computed over the part
+// of the window for which the status of the source is known (the coverage),
+// and are nil if nothing was observed within the window yet (and so are the
+// burn rates).
+type sloReport struct {
+	Window               string             `json:"window"`
+	Target               float64            `json:"target"`                 // percentage
+	Availability         *float64           `json:"availability"`           // percentage
+	Coverage             *float64           `json:"coverage"`               // percentage of the window
+	ErrorBudgetRemaining *float64           `json:"error_budget_remaining"` // percentage of the budget
+	BurnRates            map[string]float64 `json:"burn_rates"`             // by burn-rate window
+}
+
+// slo evaluates the availability of the source against the objectives.
+//
+// The error budget is the allowed unavailability (e.g. 0.1% for 99.9% target),
+// and the burn rate is the ratio of the actual unavailability within the
+// burn-rate window to the budget (so that 1 means spending the budget exactly
+// over the slo window, and 14.4 over 1h means spending 2% of 30d budget).
+func (s *Server) slo(source string, now time.Time) []sloReport {
+	reports := make([]sloReport, 0, len(s.cfg.SLO.Objectives))
+
+	for _, objective := range s.cfg.SLO.Objectives {
+		report := sloReport{
+			Window:    objective.Window,
+			Target:    objective.Target,
+			BurnRates: make(map[string]float64, len(s.cfg.SLO.BurnRateWindows)),
+		}
+		budget := 100 - objective.Target
+
+		if availability, coverage, observed := s.history.availability(source, now, objective.Duration); observed {
+			remaining := 100 * (1 - (100-availability)/budget)
+			report.Availability = &availability
+			report.Coverage = &coverage
+			report.ErrorBudgetRemaining = &remaining
+		}
+
+		for idx, window := range s.cfg.SLO.BurnRateDurations {
+			if availability, _, observed := s.history.availability(source, now, window); observed {
+				report.BurnRates[s.cfg.SLO.BurnRateWindows[idx]] = (100 - availability) / budget
+			}
+		}
+
+		reports = append(reports, report)
+	}
+
+	return reports
+}
+
+// runSLO periodically exports the slo of every source (and of the node
+// overall) as metrics.
+func (s *Server) runSLO(ctx context.Context) {
+	ticker := time.NewTicker(sloEvaluationInterval)
+	defer ticker.Stop()
+
+	sources := make([]string, 0, len(s.monitors)+1)
+	for _, m := range s.monitors {
+		sources = append(sources, m.source)
+	}
+	sources = append(sources, sourceOverall)
+
+	for {
+		now := time.Now()
+		for _, source := range sources {
+			for _, report := range s.slo(source, now) {
+				s.recordSLO(ctx, source, report)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Server) recordSLO(ctx context.Context, source string, report sloReport) {
+	attrs := []attribute.KeyValue{
+		{Key: "healthcheck_source", Value: attribute.StringValue(source)},
+		{Key: "slo_window", Value: attribute.StringValue(report.Window)},
+	}
+
+	metrics.SLOTarget.Record(ctx, report.Target/100, otelapi.WithAttributes(attrs...))
+	if report.Availability != nil {
+		metrics.SLOAvailability.Record(ctx, *report.Availability/100, otelapi.WithAttributes(attrs...))
+	}
+	if report.Coverage != nil {
+		metrics.SLOCoverage.Record(ctx, *report.Coverage/100, otelapi.WithAttributes(attrs...))
+	}
+	if report.ErrorBudgetRemaining != nil {
+		metrics.SLOErrorBudgetRemaining.Record(ctx, *report.ErrorBudgetRemaining/100, otelapi.WithAttributes(attrs...))
+	}
+	for window, rate := range report.BurnRates {
+		metrics.SLOBurnRate.Record(ctx, rate, otelapi.WithAttributes(
+			append(attrs, attribute.KeyValue{Key: "burn_rate_window", Value: attribute.StringValue(window)})...,
+		))
+	}
+}
@@ -0,0 +1,230 @@
+package server
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	otelapi "go.opentelemetry.io/otel/metric"
+
+	"github.com/flashbots/node-healthchecker/healthcheck"
+	"github.com/flashbots/node-healthchecker/metrics"
+)
+
+// sourceState is the latest known state of a healthcheck source.
+type sourceState struct {
+	Source  string             `json:"source"`
+	Status  healthcheck.Status `json:"status"`
+	Message string             `json:"message"`
+	Since   time.Time          `json:"since"`
+
+	LastSuccess time.Time     `json:"last_success"`
+	LastFailure time.Time     `json:"last_failure"`
+	Latency     time.Duration `json:"latency"`
+}
+
+const (
+	// sourceOverall is the pseudo-source that tracks the verdict at `/` (in
+	// the history, the store and the slo).
+	sourceOverall = "overall"
+)
+
+type state struct {
+	ok      map[string]bool
+	overall *sourceState
+	sources map[string]*sourceState
+
+	mx sync.Mutex
+}
+
+func newState(sources []string) *state {
+	s := &state{
+		ok:      make(map[string]bool, len(sources)),
+		sources: make(map[string]*sourceState, len(sources)),
+	}
+	now := time.Now()
+	s.overall = &sourceState{
+		Source: sourceOverall,
+		Status: healthcheck.StatusUnknown,
+		Since:  now,
+	}
+	for _, source := range sources {
+		s.ok[source] = true
+		s.sources[source] = &sourceState{
+			Source: source,
+			Status: healthcheck.StatusUnknown,
+			Since:  now,
+		}
+	}
+	return s
+}
+
+// snapshot returns the copy of the current state of all sources.
+func (s *state) snapshot() []sourceState {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	res := make([]sourceState, 0, len(s.sources))
+	for _, source := range s.sources {
+		res = append(res, *source)
+	}
+	slices.SortFunc(res, func(a, b sourceState) int {
+		switch {
+		case a.Source < b.Source:
+			return -1
+		case a.Source > b.Source:
+			return 1
+		default:
+			return 0
+		}
+	})
+	return res
+}
+
+// overallSnapshot returns the copy of the current state of the verdict at `/`.
+func (s *state) overallSnapshot() sourceState {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	return *s.overall
+}
+
+// record updates the metrics, the state and the history of the source with the
+// result of the healthcheck, and publishes the transition (if there was one).
+//
+// The skipped healthcheck is only appended to the history of results, as it
+// tells nothing about the source itself.
+func (s *Server) record(res *healthcheck.Result) {
+	if res.Skipped {
+		s.history.record(res.Source, historyResult{
+			Timestamp: time.Now(),
+			Status:    res.Status(),
+			Message:   res.Message(),
+		}, nil, time.Time{})
+		return
+	}
+
+	attrs := otelapi.WithAttributes(
+		attribute.KeyValue{Key: "healthcheck_source", Value: attribute.StringValue(res.Source)},
+	)
+
+	if res.Ok {
+		metrics.HealthcheckUp.Record(context.Background(), 1, attrs)
+		metrics.HealthchecksOkCount.Add(context.Background(), 1, attrs)
+	} else {
+		metrics.HealthcheckUp.Record(context.Background(), 0, attrs)
+		metrics.HealthchecksNokCount.Add(context.Background(), 1, attrs)
+	}
+	if res.Retries > 0 {
+		metrics.HealthchecksRetries.Add(context.Background(), int64(res.Retries), attrs)
+	}
+	if res.Latency > 0 {
+		metrics.HealthcheckLatency.Record(context.Background(), res.Latency.Seconds(), attrs)
+	}
+	metrics.NodeState.Set(res.Source, res.Metrics)
+
+	var (
+		transition *healthcheck.Transition
+		since      time.Time
+	)
+
+	s.state.mx.Lock()
+	if s.state.ok[res.Source] != res.Ok {
+		s.state.ok[res.Source] = res.Ok
+		metrics.HealthchecksFlipCount.Add(context.Background(), 1, attrs)
+	}
+	if current, known := s.state.sources[res.Source]; known {
+		now := time.Now()
+		status := res.Status()
+		if current.Status != status {
+			transition = &healthcheck.Transition{
+				Source:    res.Source,
+				From:      current.Status,
+				To:        status,
+				Message:   res.Message(),
+				Timestamp: now,
+			}
+			since = current.Since
+			current.Status = status
+			current.Since = now
+		}
+		current.Message = res.Message()
+		current.Latency = res.Latency
+		if res.Ok {
+			current.LastSuccess = now
+		} else {
+			current.LastFailure = now
+		}
+	}
+	s.state.mx.Unlock()
+
+	s.history.record(res.Source, historyResult{
+		Timestamp: time.Now(),
+		Status:    res.Status(),
+		Message:   res.Message(),
+		Duration:  res.Latency,
+	}, transition, since)
+	if transition != nil {
+		s.storeTransition(transition, since)
+	}
+
+	if transition != nil {
+		s.events.publish(transition)
+	}
+}
+
+// recordOverall updates the history of the verdict at `/` with the results of
+// the healthchecks (unless all of them were cached), and publishes the
+// transition (if there was one, which might be down to the policy alone).
+func (s *Server) recordOverall(results []*healthcheck.Result, cached []string) {
+	if len(results) == 0 {
+		return
+	}
+
+	status, errs, wrns := s.verdict("/", nil, results)
+	msg := message(append(errs, wrns...))
+
+	var (
+		transition *healthcheck.Transition
+		since      time.Time
+	)
+
+	s.state.mx.Lock()
+	now := time.Now()
+	current := s.state.overall
+	if current.Status != status {
+		transition = &healthcheck.Transition{
+			Source:    sourceOverall,
+			From:      current.Status,
+			To:        status,
+			Message:   msg,
+			Timestamp: now,
+		}
+		since = current.Since
+		current.Status = status
+		current.Since = now
+	}
+	current.Message = msg
+	if status == healthcheck.StatusError {
+		current.LastFailure = now
+	} else {
+		current.LastSuccess = now
+	}
+	s.state.mx.Unlock()
+
+	if transition == nil && len(cached) == len(results) {
+		return
+	}
+
+	s.history.record(sourceOverall, historyResult{
+		Timestamp: now,
+		Status:    status,
+		Message:   msg,
+	}, transition, since)
+	if transition != nil {
+		s.storeTransition(transition, since)
+		s.events.publish(transition)
+	}
+}
@@ -0,0 +1,135 @@
+package server
+
+import (
+	"bytes"
+	"embed"
+	"html/template"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/flashbots/node-healthchecker/config"
+	"github.com/flashbots/node-healthchecker/healthcheck"
+	"github.com/flashbots/node-healthchecker/logutils"
+)
+
+const (
+	statusRefreshInterval  = 10 * time.Second
+	statusTransitionsLimit = 50
+)
+
+var (
+	//go:embed templates/status.html
+	templates embed.FS
+
+	statusTemplate = template.Must(
+		template.New("status.html").Funcs(template.FuncMap{
+			"ago": func(t time.Time) string {
+				if t.IsZero() {
+					return "never"
+				}
+				return time.Since(t).Truncate(time.Second).String() + " ago"
+			},
+			"timestamp": func(t time.Time) string {
+				if t.IsZero() {
+					return ""
+				}
+				return t.UTC().Format(time.RFC3339)
+			},
+			"percent": func(pct *float64) string {
+				if pct == nil {
+					return "-"
+				}
+				return strconv.FormatFloat(*pct, 'f', 3, 64) + "%"
+			},
+			"burnRate": func(rates map[string]float64, window string) string {
+				rate, known := rates[window]
+				if !known {
+					return "-"
+				}
+				return strconv.FormatFloat(rate, 'f', 2, 64)
+			},
+			"latency": func(d time.Duration) string {
+				if d == 0 {
+					return "-"
+				}
+				if rounded := d.Round(time.Millisecond); rounded > 0 {
+					return rounded.String()
+				}
+				return d.Round(time.Microsecond).String()
+			},
+		}).ParseFS(templates, "templates/status.html"),
+	)
+)
+
+// statusPage is what the status page is rendered with.
+type statusPage struct {
+	Refresh     int
+	Generated   time.Time
+	Verdict     healthcheck.Status
+	Drain       drainState
+	Sources     []sourceState
+	Transitions []healthcheck.Transition
+	Settings    []config.Setting
+
+	SLO             []statusSLO
+	BurnRateWindows []string
+}
+
+// statusSLO is the state of a source against the service-level objectives.
+type statusSLO struct {
+	Source  string
+	Reports []sloReport
+}
+
+// handleStatus renders the human-readable status page (for the on-call
+// engineers, as opposed to the load-balancers).
+//
+// The page reflects the latest known state, so that viewing it does not probe
+// the nodes.
+func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
+	l := logutils.LoggerFromRequest(r)
+
+	page := &statusPage{
+		Refresh:     int(statusRefreshInterval.Seconds()),
+		Generated:   time.Now(),
+		Verdict:     s.state.overallSnapshot().Status,
+		Drain:       s.drain.get(),
+		Sources:     s.state.snapshot(),
+		Transitions: s.history.recentTransitions(statusTransitionsLimit),
+		Settings:    s.cfg.Settings(),
+	}
+
+	if len(s.cfg.SLO.Objectives) > 0 {
+		page.BurnRateWindows = s.cfg.SLO.BurnRateWindows
+		for _, m := range s.monitors {
+			page.SLO = append(page.SLO, statusSLO{
+				Source:  m.source,
+				Reports: s.slo(m.source, page.Generated),
+			})
+		}
+		page.SLO = append(page.SLO, statusSLO{
+			Source:  sourceOverall,
+			Reports: s.slo(sourceOverall, page.Generated),
+		})
+	}
+
+	var body bytes.Buffer
+	if err := statusTemplate.Execute(&body, page); err != nil {
+		l.Error("Failed to render the status page",
+			zap.Error(err),
+		)
+		http.Error(w, "failed to render the status page", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	if _, err := w.Write(body.Bytes()); err != nil {
+		l.Error("Failed to write the response body",
+			zap.Error(err),
+		)
+	}
+}
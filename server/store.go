@@ -0,0 +1,206 @@
+package server
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	otelapi "go.opentelemetry.io/otel/metric"
+	"go.uber.org/zap"
+
+	"github.com/flashbots/node-healthchecker/healthcheck"
+	"github.com/flashbots/node-healthchecker/logutils"
+	"github.com/flashbots/node-healthchecker/metrics"
+	"github.com/flashbots/node-healthchecker/store"
+)
+
+const (
+	storeCompactionInterval = time.Hour
+)
+
+// restoreHistory replays the stored transitions (within the retention period)
+// into the history, so that the uptime windows and the flip counters survive
+// restarts.
+//
+// The time between the last stored event of a source and now (while the
+// healthchecker was not running) is recorded as `unknown` status, so that it
+// doesn't count towards the uptime.
+func (s *Server) restoreHistory(ctx context.Context) error {
+	l := logutils.LoggerFromContext(ctx)
+
+	if err := s.store.Compact(); err != nil {
+		return err
+	}
+	events, err := store.Load(s.cfg.History.Store.File)
+	if err != nil {
+		return err
+	}
+
+	type replay struct {
+		ok       bool
+		flips    int64
+		status   healthcheck.Status
+		since    time.Time
+		lastSeen time.Time
+	}
+	replays := make(map[string]*replay, len(s.monitors)+1)
+	for _, m := range s.monitors {
+		replays[m.source] = &replay{ok: true, status: healthcheck.StatusUnknown}
+	}
+	replays[sourceOverall] = &replay{ok: true, status: healthcheck.StatusUnknown}
+
+	for _, event := range events {
+		r, known := replays[event.Source]
+		if !known {
+			continue
+		}
+		if event.Kind == store.KindTransition {
+			s.history.restore(&historyTransition{
+				Transition: healthcheck.Transition{
+					Source:    event.Source,
+					From:      event.From,
+					To:        event.Status,
+					Message:   event.Message,
+					Timestamp: event.Timestamp,
+				},
+				Duration: event.Duration,
+			})
+			if event.Status != healthcheck.StatusUnknown {
+				if ok := event.Status != healthcheck.StatusError; ok != r.ok {
+					r.ok = ok
+					r.flips++
+				}
+			}
+			r.since = event.Timestamp
+		}
+		r.status = event.Status
+		r.lastSeen = event.Timestamp
+	}
+
+	stopped := make([]*store.Event, 0)
+	for source, r := range replays {
+		attrs := otelapi.WithAttributes(
+			attribute.KeyValue{Key: "healthcheck_source", Value: attribute.StringValue(source)},
+		)
+		if source != sourceOverall {
+			if r.flips > 0 {
+				metrics.HealthchecksFlipCount.Add(ctx, r.flips, attrs)
+			}
+			s.state.mx.Lock()
+			s.state.ok[source] = r.ok
+			s.state.mx.Unlock()
+		}
+
+		if r.status == healthcheck.StatusUnknown || r.status == "" {
+			continue
+		}
+		transition := &historyTransition{
+			Transition: healthcheck.Transition{
+				Source:    source,
+				From:      r.status,
+				To:        healthcheck.StatusUnknown,
+				Message:   "healthchecker stopped",
+				Timestamp: r.lastSeen,
+			},
+			Duration: r.lastSeen.Sub(r.since),
+		}
+		s.history.restore(transition)
+		stopped = append(stopped, &store.Event{
+			Kind:      store.KindTransition,
+			Source:    source,
+			Timestamp: transition.Timestamp,
+			Status:    transition.To,
+			From:      transition.From,
+			Message:   transition.Message,
+			Duration:  transition.Duration,
+		})
+	}
+	if err := s.store.Append(stopped...); err != nil {
+		return err
+	}
+
+	l.Info("Restored the history",
+		zap.String("file", s.cfg.History.Store.File),
+		zap.Int("events", len(events)),
+	)
+	return nil
+}
+
+// runStore periodically samples the state of the sources into the store (so
+// that it's known until when the healthchecker was running), and drops the
+// events that are past the retention period.
+func (s *Server) runStore(ctx context.Context) {
+	l := logutils.LoggerFromContext(ctx)
+
+	sampler := time.NewTicker(s.cfg.History.Store.SampleInterval)
+	defer sampler.Stop()
+
+	compactor := time.NewTicker(storeCompactionInterval)
+	defer compactor.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-sampler.C:
+			if err := s.storeSamples(); err != nil {
+				l.Error("Failed to store the samples",
+					zap.Error(err),
+				)
+			}
+		case <-compactor.C:
+			if err := s.store.Compact(); err != nil {
+				l.Error("Failed to compact the history store",
+					zap.Error(err),
+				)
+			}
+		}
+	}
+}
+
+// storeSamples appends the current status of every (known) source to the
+// store.
+func (s *Server) storeSamples() error {
+	now := time.Now()
+	samples := make([]*store.Event, 0, len(s.monitors)+1)
+
+	s.state.mx.Lock()
+	overall := *s.state.overall
+	s.state.mx.Unlock()
+
+	for _, source := range append(s.state.snapshot(), overall) {
+		if source.Status == healthcheck.StatusUnknown {
+			continue
+		}
+		samples = append(samples, &store.Event{
+			Kind:      store.KindSample,
+			Source:    source.Source,
+			Timestamp: now,
+			Status:    source.Status,
+			Message:   source.Message,
+			Duration:  source.Latency,
+		})
+	}
+	return s.store.Append(samples...)
+}
+
+// storeTransition appends the transition to the store (if it's configured).
+func (s *Server) storeTransition(transition *healthcheck.Transition, since time.Time) {
+	if s.store == nil {
+		return
+	}
+	err := s.store.Append(&store.Event{
+		Kind:      store.KindTransition,
+		Source:    transition.Source,
+		Timestamp: transition.Timestamp,
+		Status:    transition.To,
+		From:      transition.From,
+		Message:   transition.Message,
+		Duration:  transition.Timestamp.Sub(since),
+	})
+	if err != nil {
+		s.logger.Error("Failed to store the transition",
+			zap.Error(err),
+		)
+	}
+}
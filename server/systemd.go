@@ -0,0 +1,50 @@
+package server
+
+import (
+	"context"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/flashbots/node-healthchecker/logutils"
+	"github.com/flashbots/node-healthchecker/systemd"
+)
+
+// notifySystemd updates the status line of the systemd unit and (if requested)
+// pings its watchdog.
+func (s *Server) notifySystemd(ctx context.Context, watchdog bool) {
+	if !systemd.Enabled() {
+		return
+	}
+
+	state := []string{systemd.Status(s.systemdStatus())}
+	if watchdog && s.watchdog > 0 {
+		state = append(state, systemd.Watchdog)
+	}
+
+	if err := systemd.Notify(state...); err != nil {
+		logutils.LoggerFromContext(ctx).Error("Failed to notify systemd",
+			zap.Error(err),
+		)
+	}
+}
+
+// systemdStatus summarises the state of the sources, for example:
+//
+//	geth: ok, lighthouse: error (not synced)
+func (s *Server) systemdStatus() string {
+	parts := make([]string, 0, len(s.monitors))
+	for _, source := range s.state.snapshot() {
+		part := source.Source + ": " + string(source.Status)
+		if source.Message != "" {
+			part += " (" + source.Message + ")"
+		}
+		parts = append(parts, part)
+	}
+
+	status := strings.Join(parts, ", ")
+	if s.drain.get().Draining {
+		status = "draining; " + status
+	}
+	return status
+}
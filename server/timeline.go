@@ -0,0 +1,128 @@
+package server
+
+import (
+	"time"
+
+	"github.com/flashbots/node-healthchecker/healthcheck"
+)
+
+const (
+	timelineResolution = time.Minute
+)
+
+// timeline accumulates the time that a source spent in ok/warning status (up)
+// and in any known status (observed) into the fixed-resolution buckets, so
+// that the availability over the long windows does not depend on how many
+// transitions there were within them.
+type timeline struct {
+	buckets []timelineBucket
+
+	status healthcheck.Status
+	since  time.Time // the time is accumulated up to this moment
+}
+
+type timelineBucket struct {
+	index    int64 // since unix epoch (in the units of resolution)
+	up       time.Duration
+	observed time.Duration
+}
+
+// newTimeline returns the timeline that covers (at least) the retention
+// period.
+func newTimeline(retention time.Duration) *timeline {
+	return &timeline{
+		buckets: make([]timelineBucket, retention/timelineResolution+2),
+		status:  healthcheck.StatusUnknown,
+	}
+}
+
+// set switches the status of the source at the moment.
+func (t *timeline) set(status healthcheck.Status, at time.Time) {
+	t.accumulate(at)
+	t.status = status
+}
+
+// accumulate attributes the time since the last update to the current status.
+func (t *timeline) accumulate(until time.Time) {
+	if t.since.IsZero() {
+		t.since = until
+		return
+	}
+	if !until.After(t.since) {
+		return
+	}
+
+	var up, observed bool
+	switch t.status {
+	case healthcheck.StatusOk, healthcheck.StatusWarning:
+		up, observed = true, true
+	case healthcheck.StatusError:
+		observed = true
+	}
+
+	from := t.since
+	if oldest := until.Add(-time.Duration(len(t.buckets)) * timelineResolution); from.Before(oldest) {
+		from = oldest // the rest is beyond the retention anyway
+	}
+	t.since = until
+
+	if !observed {
+		return
+	}
+
+	for from.Before(until) {
+		index := from.UnixNano() / int64(timelineResolution)
+		end := time.Unix(0, (index+1)*int64(timelineResolution))
+		if end.After(until) {
+			end = until
+		}
+
+		bucket := t.bucket(index)
+		span := end.Sub(from)
+		bucket.observed += span
+		if up {
+			bucket.up += span
+		}
+
+		from = end
+	}
+}
+
+// bucket returns the bucket with the index (resetting the stale one that it
+// replaces).
+func (t *timeline) bucket(index int64) *timelineBucket {
+	bucket := &t.buckets[index%int64(len(t.buckets))]
+	if bucket.index != index {
+		*bucket = timelineBucket{index: index}
+	}
+	return bucket
+}
+
+// availability returns the up and the observed time within the window (the
+// partially covered bucket at its start is pro-rated).
+func (t *timeline) availability(now time.Time, window time.Duration) (up, observed time.Duration) {
+	t.accumulate(now)
+
+	start := now.Add(-window)
+	first := start.UnixNano() / int64(timelineResolution)
+	last := now.UnixNano() / int64(timelineResolution)
+	if count := int64(len(t.buckets)); last-first >= count {
+		first = last - count + 1
+	}
+
+	for index := first; index <= last; index++ {
+		bucket := &t.buckets[index%int64(len(t.buckets))]
+		if bucket.index != index {
+			continue
+		}
+		share := 1.0
+		if index == first {
+			end := time.Unix(0, (index+1)*int64(timelineResolution))
+			share = min(1, float64(end.Sub(start))/float64(timelineResolution))
+		}
+		up += time.Duration(share * float64(bucket.up))
+		observed += time.Duration(share * float64(bucket.observed))
+	}
+
+	return up, observed
+}
@@ -0,0 +1,193 @@
+// Package store persists the transitions and the periodic samples of the
+// healthcheck sources in an append-only jsonl file.
+package store
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/flashbots/node-healthchecker/config"
+	"github.com/flashbots/node-healthchecker/healthcheck"
+)
+
+const (
+	KindSample     = "sample"
+	KindTransition = "transition"
+
+	maxLineLength = 1024 * 1024
+)
+
+// Event is a single line of the store.
+//
+// For transitions, Status and From are the new and the previous statuses, and
+// Duration is the time spent in the previous one. For samples, Status is the
+// current status and Duration is the latency of the latest healthcheck.
+type Event struct {
+	Kind      string             `json:"kind"`
+	Source    string             `json:"source"`
+	Timestamp time.Time          `json:"timestamp"`
+	Status    healthcheck.Status `json:"status"`
+	From      healthcheck.Status `json:"from,omitempty"`
+	Message   string             `json:"message,omitempty"`
+	Duration  time.Duration      `json:"duration,omitempty"`
+}
+
+// Store appends the events to the file.
+type Store struct {
+	cfg *config.HistoryStore
+
+	file *os.File
+	mx   sync.Mutex
+}
+
+func Open(cfg *config.HistoryStore) (*Store, error) {
+	file, err := os.OpenFile(cfg.File, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open history store: %w",
+			err,
+		)
+	}
+	return &Store{
+		cfg:  cfg,
+		file: file,
+	}, nil
+}
+
+// Load reads all events from the file, in chronological order. The lines that
+// can not be parsed (e.g. the last one, if the process crashed while writing
+// it) are skipped.
+func Load(path string) ([]*Event, error) {
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return []*Event{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open history store: %w",
+			err,
+		)
+	}
+	defer file.Close()
+
+	events := make([]*Event, 0)
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineLength)
+	for scanner.Scan() {
+		event := &Event{}
+		if err := json.Unmarshal(scanner.Bytes(), event); err != nil {
+			continue
+		}
+		events = append(events, event)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read history store: %w",
+			err,
+		)
+	}
+
+	slices.SortStableFunc(events, func(a, b *Event) int {
+		return a.Timestamp.Compare(b.Timestamp)
+	})
+	return events, nil
+}
+
+// Append writes the events to the end of the file.
+func (s *Store) Append(events ...*Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	buf := make([]byte, 0, 256*len(events))
+	for _, event := range events {
+		line, err := json.Marshal(event)
+		if err != nil {
+			return err
+		}
+		buf = append(append(buf, line...), '\n')
+	}
+
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	if _, err := s.file.Write(buf); err != nil {
+		return fmt.Errorf("failed to write to history store: %w",
+			err,
+		)
+	}
+	return nil
+}
+
+// Compact drops the events that are older than the retention period.
+func (s *Store) Compact() error {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	events, err := Load(s.cfg.File)
+	if err != nil {
+		return err
+	}
+	cutoff := time.Now().Add(-s.cfg.Retention)
+	events = slices.DeleteFunc(events, func(e *Event) bool {
+		return e.Timestamp.Before(cutoff)
+	})
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.cfg.File), filepath.Base(s.cfg.File)+".*")
+	if err != nil {
+		return fmt.Errorf("failed to compact history store: %w",
+			err,
+		)
+	}
+	defer os.Remove(tmp.Name())
+
+	w := bufio.NewWriter(tmp)
+	for _, event := range events {
+		line, err := json.Marshal(event)
+		if err != nil {
+			tmp.Close()
+			return err
+		}
+		if _, err := w.Write(append(line, '\n')); err != nil {
+			tmp.Close()
+			return err
+		}
+	}
+	if err := w.Flush(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	// the compacted file is opened before it replaces the current one, so that
+	// any failure leaves the store appending to the file that is still there
+	file, err := os.OpenFile(tmp.Name(), os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to reopen history store: %w",
+			err,
+		)
+	}
+	if err := os.Rename(tmp.Name(), s.cfg.File); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to compact history store: %w",
+			err,
+		)
+	}
+	s.file.Close()
+	s.file = file
+
+	return nil
+}
+
+func (s *Store) Close() error {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	return s.file.Close()
+}
@@ -0,0 +1,85 @@
+// Package systemd implements the bits of the sd_notify protocol that are needed
+// to run the healthchecker as a `Type=notify` unit (with `WatchdogSec`).
+//
+// See: https://www.freedesktop.org/software/systemd/man/latest/sd_notify.html
+package systemd
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	Ready    = "READY=1"
+	Stopping = "STOPPING=1"
+	Watchdog = "WATCHDOG=1"
+)
+
+var (
+	errInvalidWatchdog = errors.New("invalid watchdog settings")
+)
+
+// Enabled returns true if the process is started by systemd as a notify unit.
+func Enabled() bool {
+	return os.Getenv("NOTIFY_SOCKET") != ""
+}
+
+// Notify sends the state (e.g. `READY=1`) to systemd. It does nothing if the
+// process is not run as a notify unit.
+func Notify(state ...string) error {
+	socket := os.Getenv("NOTIFY_SOCKET")
+	if socket == "" {
+		return nil
+	}
+	if strings.HasPrefix(socket, "@") { // abstract namespace
+		socket = "\x00" + socket[1:]
+	}
+
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{
+		Name: socket,
+		Net:  "unixgram",
+	})
+	if err != nil {
+		return fmt.Errorf("failed to connect to systemd notify socket: %w",
+			err,
+		)
+	}
+	defer conn.Close()
+
+	if _, err := conn.Write([]byte(strings.Join(state, "\n"))); err != nil {
+		return fmt.Errorf("failed to notify systemd: %w",
+			err,
+		)
+	}
+	return nil
+}
+
+// Status formats the free-form status line that systemd shows for the unit.
+func Status(status string) string {
+	return "STATUS=" + strings.ReplaceAll(status, "\n", " ")
+}
+
+// WatchdogInterval returns the interval within which systemd expects the
+// watchdog pings (zero if the watchdog is not enabled for this process).
+func WatchdogInterval() (time.Duration, error) {
+	usec := os.Getenv("WATCHDOG_USEC")
+	if usec == "" {
+		return 0, nil
+	}
+	if pid := os.Getenv("WATCHDOG_PID"); pid != "" && pid != strconv.Itoa(os.Getpid()) {
+		return 0, nil // the watchdog is meant for another process
+	}
+
+	interval, err := strconv.ParseUint(usec, 10, 64)
+	if err != nil || interval == 0 {
+		return 0, fmt.Errorf("%w: WATCHDOG_USEC=%s",
+			errInvalidWatchdog, usec,
+		)
+	}
+	return time.Duration(interval) * time.Microsecond, nil
+}
@@ -0,0 +1,56 @@
+package tracing
+
+import (
+	"context"
+	"strings"
+
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/sdk/trace"
+	"google.golang.org/grpc/credentials"
+
+	"github.com/flashbots/node-healthchecker/config"
+)
+
+func newOTLPExporter(ctx context.Context, cfg *config.OTLP) (trace.SpanExporter, error) {
+	// the endpoint can be either the url (with scheme and, for http, path) or
+	// just host:port
+
+	hasScheme := strings.Contains(cfg.Endpoint, "://")
+
+	switch cfg.Protocol {
+	case config.OTLPProtocolHTTP:
+		opts := []otlptracehttp.Option{
+			otlptracehttp.WithHeaders(cfg.Headers),
+		}
+		if hasScheme {
+			opts = append(opts, otlptracehttp.WithEndpointURL(cfg.Endpoint))
+		} else {
+			opts = append(opts, otlptracehttp.WithEndpoint(cfg.Endpoint))
+		}
+		switch {
+		case cfg.Insecure:
+			opts = append(opts, otlptracehttp.WithInsecure())
+		case cfg.TLS != nil:
+			opts = append(opts, otlptracehttp.WithTLSClientConfig(cfg.TLS))
+		}
+		return otlptracehttp.New(ctx, opts...)
+
+	default:
+		opts := []otlptracegrpc.Option{
+			otlptracegrpc.WithHeaders(cfg.Headers),
+		}
+		if hasScheme {
+			opts = append(opts, otlptracegrpc.WithEndpointURL(cfg.Endpoint))
+		} else {
+			opts = append(opts, otlptracegrpc.WithEndpoint(cfg.Endpoint))
+		}
+		switch {
+		case cfg.Insecure:
+			opts = append(opts, otlptracegrpc.WithInsecure())
+		case cfg.TLS != nil:
+			opts = append(opts, otlptracegrpc.WithTLSCredentials(credentials.NewTLS(cfg.TLS)))
+		}
+		return otlptracegrpc.New(ctx, opts...)
+	}
+}
@@ -0,0 +1,64 @@
+package tracing
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/trace"
+	oteltrace "go.opentelemetry.io/otel/trace"
+
+	"github.com/flashbots/node-healthchecker/config"
+	"github.com/flashbots/node-healthchecker/utils"
+)
+
+const (
+	tracerName = "node-healthchecker"
+)
+
+var (
+	provider *trace.TracerProvider
+)
+
+// Setup configures the exporter of the traces (if the otlp endpoint is set,
+// otherwise the spans are no-op).
+func Setup(ctx context.Context, cfg *config.Tracing, resourceAttributes map[string]string) error {
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	if cfg.OTLP.Endpoint == "" {
+		return nil
+	}
+
+	res, err := utils.OtelResource(ctx, tracerName, resourceAttributes)
+	if err != nil {
+		return err
+	}
+
+	exporter, err := newOTLPExporter(ctx, &cfg.OTLP)
+	if err != nil {
+		return err
+	}
+
+	provider = trace.NewTracerProvider(
+		trace.WithBatcher(exporter),
+		trace.WithResource(res),
+		trace.WithSampler(trace.ParentBased(trace.TraceIDRatioBased(cfg.SampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+
+	return nil
+}
+
+// Shutdown flushes the spans that are yet to be exported.
+func Shutdown(ctx context.Context) error {
+	if provider == nil {
+		return nil
+	}
+	return provider.Shutdown(ctx)
+}
+
+// Start starts a span (which is a child of the span from the context, if
+// there's one).
+func Start(ctx context.Context, name string, opts ...oteltrace.SpanStartOption) (context.Context, oteltrace.Span) {
+	return otel.Tracer(tracerName).Start(ctx, name, opts...)
+}
@@ -0,0 +1,115 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var (
+	errJSONPathInvalid  = errors.New("invalid json path")
+	errJSONPathNotFound = errors.New("json path not found")
+)
+
+// JSONPath is a minimal subset of JSONPath that supports only the dot-notation
+// for the object members and the bracket-notation for array indices, e.g.:
+//
+//	$.result.transactions[0].hash
+type JSONPath []any
+
+func ParseJSONPath(path string) (JSONPath, error) {
+	str := strings.TrimPrefix(strings.TrimPrefix(path, "$"), ".")
+	res := make(JSONPath, 0)
+
+	for str != "" {
+		switch str[0] {
+		case '[':
+			end := strings.IndexByte(str, ']')
+			if end == -1 {
+				return nil, fmt.Errorf("%w: unterminated index: %s",
+					errJSONPathInvalid, path,
+				)
+			}
+			idx, err := strconv.Atoi(str[1:end])
+			if err != nil || idx < 0 {
+				return nil, fmt.Errorf("%w: invalid index '%s': %s",
+					errJSONPathInvalid, str[1:end], path,
+				)
+			}
+			res = append(res, idx)
+			str = strings.TrimPrefix(str[end+1:], ".")
+		default:
+			end := strings.IndexAny(str, ".[")
+			if end == -1 {
+				end = len(str)
+			}
+			if end == 0 {
+				return nil, fmt.Errorf("%w: empty member name: %s",
+					errJSONPathInvalid, path,
+				)
+			}
+			res = append(res, str[:end])
+			str = str[end:]
+			if strings.HasPrefix(str, ".") {
+				str = str[1:]
+				if str == "" {
+					return nil, fmt.Errorf("%w: trailing dot: %s",
+						errJSONPathInvalid, path,
+					)
+				}
+			}
+		}
+	}
+
+	return res, nil
+}
+
+// Lookup returns the value that path points to inside of the document that was
+// unmarshalled with `encoding/json` into `any`.
+func (p JSONPath) Lookup(doc any) (any, error) {
+	val := doc
+	for _, segment := range p {
+		switch segment := segment.(type) {
+		case string:
+			obj, ok := val.(map[string]any)
+			if !ok {
+				return nil, fmt.Errorf("%w: not an object at '%s'",
+					errJSONPathNotFound, segment,
+				)
+			}
+			if val, ok = obj[segment]; !ok {
+				return nil, fmt.Errorf("%w: no member '%s'",
+					errJSONPathNotFound, segment,
+				)
+			}
+		case int:
+			arr, ok := val.([]any)
+			if !ok {
+				return nil, fmt.Errorf("%w: not an array at [%d]",
+					errJSONPathNotFound, segment,
+				)
+			}
+			if segment >= len(arr) {
+				return nil, fmt.Errorf("%w: index [%d] is out of range (length %d)",
+					errJSONPathNotFound, segment, len(arr),
+				)
+			}
+			val = arr[segment]
+		}
+	}
+	return val, nil
+}
+
+func (p JSONPath) String() string {
+	str := "$"
+	for _, segment := range p {
+		switch segment := segment.(type) {
+		case string:
+			str += "." + segment
+		case int:
+			str += "[" + strconv.Itoa(segment) + "]"
+		}
+	}
+	return str
+}
@@ -0,0 +1,24 @@
+package utils
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/resource"
+)
+
+// OtelResource describes the healthchecker to the OpenTelemetry collector (the
+// attributes override the ones from OTEL_RESOURCE_ATTRIBUTES env variable).
+func OtelResource(ctx context.Context, service string, attributes map[string]string) (*resource.Resource, error) {
+	attrs := []attribute.KeyValue{
+		attribute.String("service.name", service),
+	}
+	for key, value := range attributes {
+		attrs = append(attrs, attribute.String(key, value))
+	}
+
+	return resource.New(ctx,
+		resource.WithFromEnv(),
+		resource.WithAttributes(attrs...),
+	)
+}
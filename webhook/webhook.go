@@ -0,0 +1,296 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"text/template"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/flashbots/node-healthchecker/config"
+	"github.com/flashbots/node-healthchecker/healthcheck"
+	"github.com/flashbots/node-healthchecker/logutils"
+)
+
+var (
+	errWebhookFailed = errors.New("webhook failed")
+)
+
+// Payload is what the webhook template is rendered with (and what is sent
+// as-is if there is no template).
+type Payload struct {
+	healthcheck.Transition
+
+	Instance string `json:"instance"`
+}
+
+// Notifier posts the transitions to the configured webhooks.
+type Notifier struct {
+	cfg *config.Webhook
+
+	client    *http.Client
+	endpoints []*endpoint
+	instance  string
+}
+
+type endpoint struct {
+	cfg   *config.WebhookEndpoint
+	index int
+
+	queue    []*Payload // unbounded, so that slow endpoints miss nothing
+	queued   chan struct{}
+	template *template.Template
+
+	sent map[string]time.Time // for de-duplication
+	mx   sync.Mutex
+}
+
+var funcs = template.FuncMap{
+	"json": func(v any) (string, error) {
+		b, err := json.Marshal(v)
+		if err != nil {
+			return "", err
+		}
+		return string(b), nil
+	},
+}
+
+func New(cfg *config.Webhook) (*Notifier, error) {
+	instance, _ := os.Hostname()
+
+	n := &Notifier{
+		cfg:       cfg,
+		client:    &http.Client{Timeout: cfg.Timeout},
+		endpoints: make([]*endpoint, 0, len(cfg.Endpoints)),
+		instance:  instance,
+	}
+
+	for idx, e := range cfg.Endpoints {
+		ep := &endpoint{
+			cfg:    e,
+			index:  idx,
+			queued: make(chan struct{}, 1),
+			sent:   make(map[string]time.Time),
+		}
+		if e.Template != "" {
+			tmpl, err := template.New(e.Host()).Funcs(funcs).Parse(e.Template)
+			if err != nil {
+				return nil, fmt.Errorf("invalid template of webhook '%s': %w",
+					e.Host(), err,
+				)
+			}
+			ep.template = tmpl
+		}
+		n.endpoints = append(n.endpoints, ep)
+	}
+
+	return n, nil
+}
+
+// Run delivers the transitions to the webhooks until the context is cancelled.
+func (n *Notifier) Run(ctx context.Context, transitions <-chan *healthcheck.Transition) {
+	for _, e := range n.endpoints {
+		go n.deliver(ctx, e)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case t := <-transitions:
+			payload := &Payload{
+				Transition: *t,
+				Instance:   n.instance,
+			}
+			for _, e := range n.endpoints {
+				if e.accepts(t, n.cfg.DedupWindow) {
+					e.enqueue(payload)
+				}
+			}
+		}
+	}
+}
+
+// accepts returns true if the transition should be sent to the endpoint.
+func (e *endpoint) accepts(t *healthcheck.Transition, dedupWindow time.Duration) bool {
+	if max(severity(t.From), severity(t.To)) < severity(healthcheck.Status(e.cfg.MinSeverity)) {
+		return false
+	}
+
+	if dedupWindow == 0 {
+		return true
+	}
+
+	e.mx.Lock()
+	defer e.mx.Unlock()
+
+	key := t.Source + "/" + string(t.To)
+	if last, seen := e.sent[key]; seen && t.Timestamp.Sub(last) < dedupWindow {
+		return false
+	}
+	e.sent[key] = t.Timestamp
+
+	return true
+}
+
+func (e *endpoint) enqueue(payload *Payload) {
+	e.mx.Lock()
+	e.queue = append(e.queue, payload)
+	e.mx.Unlock()
+
+	select {
+	case e.queued <- struct{}{}:
+	default:
+	}
+}
+
+func (e *endpoint) dequeue() *Payload {
+	e.mx.Lock()
+	defer e.mx.Unlock()
+
+	if len(e.queue) == 0 {
+		return nil
+	}
+	payload := e.queue[0]
+	e.queue[0] = nil
+	e.queue = e.queue[1:]
+	return payload
+}
+
+func (n *Notifier) deliver(ctx context.Context, e *endpoint) {
+	l := logutils.LoggerFromContext(ctx).With(
+		zap.Int("webhook_index", e.index),
+		zap.String("webhook_host", e.cfg.Host()),
+	)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-e.queued:
+		}
+
+		for payload := e.dequeue(); payload != nil; payload = e.dequeue() {
+			body, err := e.render(payload)
+			if err != nil {
+				l.Error("Failed to render the webhook body",
+					zap.Error(err),
+				)
+				continue
+			}
+
+			delay := n.cfg.RetryDelay
+			for attempt := 0; ; attempt++ {
+				err = n.post(ctx, e, body)
+				if err == nil || attempt >= n.cfg.Retries || !retryable(err) {
+					break
+				}
+				l.Debug("Webhook failed; retrying...",
+					zap.Error(err),
+					zap.Int("attempt", attempt+1),
+					zap.Duration("delay", delay),
+				)
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(delay):
+				}
+				delay *= 2
+			}
+			if err != nil {
+				l.Error("Failed to deliver the webhook",
+					zap.Error(err),
+					zap.String("source", payload.Source),
+					zap.String("status", string(payload.To)),
+				)
+			}
+		}
+	}
+}
+
+func (e *endpoint) render(payload *Payload) ([]byte, error) {
+	if e.template == nil {
+		return json.Marshal(payload)
+	}
+	var buf bytes.Buffer
+	if err := e.template.Execute(&buf, payload); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (n *Notifier) post(ctx context.Context, e *endpoint, body []byte) error {
+	req, err := http.NewRequestWithContext(
+		ctx,
+		http.MethodPost,
+		e.cfg.URL,
+		bytes.NewReader(body),
+	)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("content-type", "application/json")
+	for k, v := range e.cfg.Headers {
+		req.Header.Set(k, v)
+	}
+
+	res, err := n.client.Do(req)
+	if err != nil {
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) { // the url is the credential for some webhooks
+			urlErr.URL = e.cfg.Host()
+		}
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+		return &statusError{
+			status: res.StatusCode,
+			body:   string(msg),
+		}
+	}
+
+	return nil
+}
+
+type statusError struct {
+	status int
+	body   string
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("%s: unexpected HTTP status '%d': %s",
+		errWebhookFailed, e.status, e.body,
+	)
+}
+
+// retryable returns false for the errors that will not go away with a retry.
+func retryable(err error) bool {
+	var se *statusError
+	if errors.As(err, &se) {
+		return se.status >= 500 || se.status == http.StatusTooManyRequests
+	}
+	return true
+}
+
+func severity(status healthcheck.Status) int {
+	switch status {
+	case healthcheck.StatusWarning:
+		return 1
+	case healthcheck.StatusError:
+		return 2
+	default:
+		return 0
+	}
+}